@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -55,6 +56,86 @@ func (w *wrappedWriter) WriteHeader(statusCode int) {
 	w.ResponseWriter.WriteHeader(statusCode)
 }
 
+// loadExporterOptions builds the exporter options from environment variables
+func loadExporterOptions() exporters.Options {
+	opts := exporters.DefaultOptions()
+	opts.PeerSystemInfo = utils.GetEnvBool("NETBIRD_PEER_SYSTEM_INFO", opts.PeerSystemInfo)
+	opts.ExcludeEphemeralPeers = utils.GetEnvBool("NETBIRD_EXCLUDE_EPHEMERAL_PEERS", opts.ExcludeEphemeralPeers)
+	opts.PeerGeoNameID = utils.GetEnvBool("NETBIRD_PEER_GEONAME_ID", opts.PeerGeoNameID)
+	opts.UserPeersThreshold = utils.GetEnvInt("NETBIRD_USER_PEERS_THRESHOLD", opts.UserPeersThreshold)
+	opts.PeerAvailability = utils.GetEnvBool("NETBIRD_PEER_AVAILABILITY", opts.PeerAvailability)
+	opts.PeerAvailabilityStateFile = utils.GetEnvWithDefault("NETBIRD_PEER_AVAILABILITY_STATE_FILE", opts.PeerAvailabilityStateFile)
+	opts.OSFamilyMapping = utils.GetEnvMap("NETBIRD_OS_FAMILY_MAPPING", opts.OSFamilyMapping)
+	opts.PeerGroupOverlap = utils.GetEnvList("NETBIRD_PEER_GROUP_OVERLAP", opts.PeerGroupOverlap)
+	opts.UserTokens = utils.GetEnvBool("NETBIRD_USER_TOKENS", opts.UserTokens)
+	opts.UserPermissionsPerUser = utils.GetEnvBool("NETBIRD_USER_PERMISSIONS_PER_USER", opts.UserPermissionsPerUser)
+	opts.UserPermissionsModules = utils.GetEnvList("NETBIRD_USER_PERMISSIONS_MODULES", opts.UserPermissionsModules)
+	opts.SetupKeyLeakThreshold = utils.GetEnvInt("NETBIRD_SETUP_KEY_LEAK_THRESHOLD", opts.SetupKeyLeakThreshold)
+	opts.PolicyWidePortRange = utils.GetEnvInt("NETBIRD_POLICY_WIDE_PORT_RANGE", opts.PolicyWidePortRange)
+
+	granularity, err := exporters.ParseGeoGranularity(utils.GetEnvWithDefault("NETBIRD_PEER_GEO_GRANULARITY", string(opts.PeerGeoGranularity)))
+	if err != nil {
+		logrus.WithError(err).Warn("Invalid peer geo granularity, using default")
+	} else {
+		opts.PeerGeoGranularity = granularity
+	}
+	// GeoNames IDs identify cities, so they are only known at city granularity
+	if opts.PeerGeoNameID && opts.PeerGeoGranularity != exporters.GeoGranularityCity {
+		logrus.WithField("granularity", opts.PeerGeoGranularity).Warn("NETBIRD_PEER_GEONAME_ID requires city geo granularity, ignoring it")
+		opts.PeerGeoNameID = false
+	}
+
+	if values := utils.GetEnvList("NETBIRD_USER_INACTIVE_PERIODS", nil); len(values) > 0 {
+		periods, err := exporters.ParsePeriods(values)
+		if err != nil {
+			logrus.WithError(err).Warn("Invalid user inactive periods, using default")
+		} else {
+			opts.UserInactivePeriods = periods
+		}
+	}
+
+	if names := utils.GetEnvList("NETBIRD_POLICY_RULE_CHECKS", nil); len(names) == 1 && names[0] == "none" {
+		opts.PolicyRuleChecks = nil
+	} else if len(names) > 0 {
+		checks, err := exporters.SelectPolicyRuleChecks(names)
+		if err != nil {
+			logrus.WithError(err).Warn("Invalid policy rule checks, using default")
+		} else {
+			opts.PolicyRuleChecks = checks
+		}
+	}
+
+	if values := utils.GetEnvList("NETBIRD_POLICY_SENSITIVE_PORTS", nil); len(values) > 0 {
+		ports, err := exporters.ParsePorts(values)
+		if err != nil {
+			logrus.WithError(err).Warn("Invalid policy sensitive ports, using default")
+		} else {
+			opts.PolicySensitivePorts = ports
+		}
+	}
+
+	return opts
+}
+
+// loadLabelPrivacy builds the label privacy rules from environment variables,
+// returning nil when the variable is unset. A variable that is set but yields
+// no valid rules is an error, so privacy is never disabled by a typo.
+func loadLabelPrivacy() (*exporters.LabelPrivacy, error) {
+	value := os.Getenv("NETBIRD_LABEL_PRIVACY")
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	modes, err := exporters.ParseLabelPrivacy(utils.GetEnvList("NETBIRD_LABEL_PRIVACY", nil))
+	if err != nil {
+		return nil, err
+	}
+	if len(modes) == 0 {
+		return nil, fmt.Errorf("no label privacy rules found in %q", value)
+	}
+	return exporters.NewLabelPrivacy(modes, os.Getenv("NETBIRD_LABEL_PRIVACY_KEY"))
+}
+
 func main() {
 	// Configuration from environment variables
 	netbirdURL := utils.GetEnvWithDefault("NETBIRD_API_URL", "https://api.netbird.io")
@@ -94,6 +175,25 @@ func main() {
 		fmt.Fprintf(os.Stderr, "    LISTEN_ADDRESS: HTTP server listen address (default: :8080)\\n")
 		fmt.Fprintf(os.Stderr, "    METRICS_PATH: Metrics endpoint path (default: /metrics)\\n")
 		fmt.Fprintf(os.Stderr, "    LOG_LEVEL: Logging level (default: info)\\n")
+		fmt.Fprintf(os.Stderr, "    NETBIRD_PEER_SYSTEM_INFO: Expose per-peer hardware and system info (default: false)\\n")
+		fmt.Fprintf(os.Stderr, "    NETBIRD_EXCLUDE_EPHEMERAL_PEERS: Omit ephemeral peers from per-peer series (default: false)\\n")
+		fmt.Fprintf(os.Stderr, "    NETBIRD_PEER_GEO_GRANULARITY: Peer location detail: none, country or city (default: city)\\n")
+		fmt.Fprintf(os.Stderr, "    NETBIRD_PEER_GEONAME_ID: Add GeoNames IDs to peer location metrics (default: false)\\n")
+		fmt.Fprintf(os.Stderr, "    NETBIRD_USER_PEERS_THRESHOLD: Peer count above which users are reported (default: 5)\\n")
+		fmt.Fprintf(os.Stderr, "    NETBIRD_PEER_AVAILABILITY: Track rolling peer availability ratios (default: false)\\n")
+		fmt.Fprintf(os.Stderr, "    NETBIRD_PEER_AVAILABILITY_STATE_FILE: File to persist peer availability history (default: none)\\n")
+		fmt.Fprintf(os.Stderr, "    NETBIRD_OS_FAMILY_MAPPING: Extra OS substring=family mappings, comma-separated (default: none)\\n")
+		fmt.Fprintf(os.Stderr, "    NETBIRD_PEER_GROUP_OVERLAP: Group names or IDs to export pairwise peer overlap for, comma-separated (default: disabled)\\n")
+		fmt.Fprintf(os.Stderr, "    NETBIRD_USER_TOKENS: Export personal access token expiry and last use, one API request per service user (default: false)\\n")
+		fmt.Fprintf(os.Stderr, "    NETBIRD_USER_INACTIVE_PERIODS: Login age thresholds for inactive users, comma-separated (default: 30d,90d,180d)\\n")
+		fmt.Fprintf(os.Stderr, "    NETBIRD_USER_PERMISSIONS_PER_USER: Export the per-user permission matrix for all modules (default: true)\\n")
+		fmt.Fprintf(os.Stderr, "    NETBIRD_USER_PERMISSIONS_MODULES: Modules exported per user when the matrix is disabled, comma-separated (default: none)\\n")
+		fmt.Fprintf(os.Stderr, "    NETBIRD_SETUP_KEY_LEAK_THRESHOLD: Hourly enrollments above which a reusable setup key is flagged (default: 10)\\n")
+		fmt.Fprintf(os.Stderr, "    NETBIRD_POLICY_RULE_CHECKS: Policy rule checks to run, comma-separated, or none (default: all)\\n")
+		fmt.Fprintf(os.Stderr, "    NETBIRD_POLICY_SENSITIVE_PORTS: Ports reported by netbird_policy_rules_by_port, comma-separated (default: 22,23,445,1433,3306,3389,5432,5900,6379,27017)\\n")
+		fmt.Fprintf(os.Stderr, "    NETBIRD_POLICY_WIDE_PORT_RANGE: Port range size above which a policy rule is flagged (default: 1000)\\n")
+		fmt.Fprintf(os.Stderr, "    NETBIRD_LABEL_PRIVACY: Per-label privacy rules as label=keep|drop|hash, comma-separated (default: none)\\n")
+		fmt.Fprintf(os.Stderr, "    NETBIRD_LABEL_PRIVACY_KEY: Secret key for hashed label values (required when hashing)\\n")
 		fmt.Fprintf(os.Stderr, "  Use --help or -h to display this message.\\n")
 		os.Exit(0)
 	}
@@ -103,6 +203,14 @@ func main() {
 		logrus.Fatal("NETBIRD_API_TOKEN environment variable is required")
 	}
 
+	opts := loadExporterOptions()
+
+	// Refuse to start rather than expose PII when the privacy rules are invalid
+	privacy, err := loadLabelPrivacy()
+	if err != nil {
+		logrus.WithError(err).Fatal("Invalid label privacy configuration")
+	}
+
 	logrus.WithFields(logrus.Fields{
 		"netbird_url":  netbirdURL,
 		"listen_addr":  listenAddr,
@@ -110,8 +218,29 @@ func main() {
 		"log_level":    logLevel,
 	}).Info("Starting NetBird API Exporter")
 
+	logrus.WithFields(logrus.Fields{
+		"peer_system_info":         opts.PeerSystemInfo,
+		"exclude_ephemeral_peers":  opts.ExcludeEphemeralPeers,
+		"peer_geo_granularity":     opts.PeerGeoGranularity,
+		"peer_geoname_id":          opts.PeerGeoNameID,
+		"user_peers_threshold":     opts.UserPeersThreshold,
+		"peer_availability":        opts.PeerAvailability,
+		"peer_availability_state":  opts.PeerAvailabilityStateFile,
+		"os_family_mappings":       len(opts.OSFamilyMapping),
+		"peer_group_overlap":       opts.PeerGroupOverlap,
+		"user_tokens":              opts.UserTokens,
+		"user_inactive_periods":    len(opts.UserInactivePeriods),
+		"user_permissions_matrix":  opts.UserPermissionsPerUser,
+		"user_permission_modules":  opts.UserPermissionsModules,
+		"setup_key_leak_threshold": opts.SetupKeyLeakThreshold,
+		"policy_rule_checks":       len(opts.PolicyRuleChecks),
+		"policy_sensitive_ports":   opts.PolicySensitivePorts,
+		"policy_wide_port_range":   opts.PolicyWidePortRange,
+		"label_privacy":            privacy != nil,
+	}).Debug("Loaded exporter options")
+
 	// Create exporter
-	exporter := exporters.NewNetBirdExporter(netbirdURL, netbirdToken)
+	exporter := exporters.NewNetBirdExporterWithOptions(netbirdURL, netbirdToken, opts)
 
 	// Register exporter
 	prometheus.MustRegister(exporter)
@@ -125,8 +254,13 @@ func main() {
 		handler = debugLoggingMiddleware(mux)
 	}
 
-	// Metrics endpoint
-	mux.Handle(metricsPath, promhttp.Handler())
+	// Metrics endpoint, rewriting PII labels when privacy rules are configured
+	if privacy != nil {
+		gatherer := privacy.Gatherer(prometheus.DefaultGatherer)
+		mux.Handle(metricsPath, promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer, promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{})))
+	} else {
+		mux.Handle(metricsPath, promhttp.Handler())
+	}
 
 	// Health check endpoint
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -202,5 +336,8 @@ func main() {
 	}
 
 	<-ctx.Done()
+	if err := exporter.SaveState(); err != nil {
+		logrus.WithError(err).Error("Failed to save exporter state")
+	}
 	logrus.Info("Server stopped")
 }
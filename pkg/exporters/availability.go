@@ -0,0 +1,290 @@
+package exporters
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// availabilityMinGap is the lower bound of the longest interval between polls
+	// that is still counted; longer gaps (e.g. while the exporter was down) are
+	// treated as unobserved
+	availabilityMinGap = 15 * time.Minute
+	// availabilityGapFactor scales the median scrape interval into the gap limit,
+	// so that slow scrape intervals are still counted
+	availabilityGapFactor = 3
+	// availabilityIntervalSamples is the number of recent scrape intervals the
+	// median is taken over
+	availabilityIntervalSamples = 10
+	// availabilityPersistInterval limits how often the state file is rewritten
+	availabilityPersistInterval = 15 * time.Minute
+)
+
+// availabilityTiers are the bucket resolutions at which connection time is
+// accumulated. Short windows use fine buckets; long windows use coarse ones so
+// that a week of history stays small in memory and in the state file.
+var availabilityTiers = []struct {
+	bucketSize time.Duration
+	retention  time.Duration
+}{
+	{5 * time.Minute, time.Hour},
+	{time.Hour, 7 * 24 * time.Hour},
+}
+
+// availabilityWindows are the rolling windows exported for each peer
+var availabilityWindows = []struct {
+	label    string
+	duration time.Duration
+}{
+	{"1h", time.Hour},
+	{"24h", 24 * time.Hour},
+	{"7d", 7 * 24 * time.Hour},
+}
+
+// availabilityBucket accumulates observed and connected seconds for one bucket
+type availabilityBucket struct {
+	Start     int64
+	Connected float64
+	Observed  float64
+}
+
+// MarshalJSON encodes a bucket as a compact [start, connected, observed] array
+// with whole seconds
+func (b availabilityBucket) MarshalJSON() ([]byte, error) {
+	return json.Marshal([3]int64{b.Start, int64(math.Round(b.Connected)), int64(math.Round(b.Observed))})
+}
+
+// UnmarshalJSON decodes a bucket written by MarshalJSON
+func (b *availabilityBucket) UnmarshalJSON(data []byte) error {
+	var values [3]int64
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	b.Start, b.Connected, b.Observed = values[0], float64(values[1]), float64(values[2])
+	return nil
+}
+
+// peerAvailability holds the connection history of a single peer, with one
+// bucket list per availability tier
+type peerAvailability struct {
+	LastSample    time.Time              `json:"last_sample"`
+	LastConnected bool                   `json:"last_connected"`
+	Tiers         [][]availabilityBucket `json:"tiers"`
+}
+
+// availabilityTracker keeps a rolling window of connection samples per peer
+type availabilityTracker struct {
+	mu            sync.Mutex
+	peers         map[string]*peerAvailability
+	lastPersisted time.Time
+	encodedSeq    uint64
+
+	// Recent intervals between scrapes, from which the gap limit is derived
+	lastScrape time.Time
+	intervals  []time.Duration
+	maxGap     time.Duration
+
+	// writeMu serializes state file writes, which happen outside mu
+	writeMu    sync.Mutex
+	writtenSeq uint64
+}
+
+// newAvailabilityTracker creates an empty tracker
+func newAvailabilityTracker() *availabilityTracker {
+	return &availabilityTracker{
+		peers:  make(map[string]*peerAvailability),
+		maxGap: availabilityMinGap,
+	}
+}
+
+// observeScrape records the start of a scrape and updates the gap limit to a
+// multiple of the median scrape interval. It reports whether the interval
+// since the previous scrape is short enough to be counted.
+func (t *availabilityTracker) observeScrape(now time.Time) (time.Duration, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	previous := t.lastScrape
+	t.lastScrape = now
+	if previous.IsZero() || !now.After(previous) {
+		return 0, true
+	}
+
+	interval := now.Sub(previous)
+	t.intervals = append(t.intervals, interval)
+	if len(t.intervals) > availabilityIntervalSamples {
+		t.intervals = t.intervals[1:]
+	}
+
+	sorted := slices.Clone(t.intervals)
+	slices.Sort(sorted)
+	t.maxGap = max(availabilityMinGap, availabilityGapFactor*sorted[len(sorted)/2])
+
+	return interval, interval <= t.maxGap
+}
+
+// record adds a connection sample for a peer. The time elapsed since the previous
+// sample is attributed to the previously observed state.
+func (t *availabilityTracker) record(peerID string, connected bool, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	peer, ok := t.peers[peerID]
+	if !ok {
+		t.peers[peerID] = &peerAvailability{LastSample: now, LastConnected: connected}
+		return
+	}
+
+	if len(peer.Tiers) != len(availabilityTiers) {
+		peer.Tiers = make([][]availabilityBucket, len(availabilityTiers))
+	}
+
+	elapsed := now.Sub(peer.LastSample)
+	for i, tier := range availabilityTiers {
+		buckets := peer.Tiers[i]
+		if elapsed > 0 && elapsed <= t.maxGap {
+			start := now.Truncate(tier.bucketSize).Unix()
+			if n := len(buckets); n == 0 || buckets[n-1].Start != start {
+				buckets = append(buckets, availabilityBucket{Start: start})
+			}
+			bucket := &buckets[len(buckets)-1]
+			bucket.Observed += elapsed.Seconds()
+			if peer.LastConnected {
+				bucket.Connected += elapsed.Seconds()
+			}
+		}
+
+		// Drop buckets that fell out of the tier's retention
+		cutoff := now.Add(-tier.retention).Unix()
+		expired := 0
+		for expired < len(buckets) && buckets[expired].Start+int64(tier.bucketSize.Seconds()) <= cutoff {
+			expired++
+		}
+		peer.Tiers[i] = buckets[expired:]
+	}
+
+	peer.LastSample = now
+	peer.LastConnected = connected
+}
+
+// ratio returns the share of observed time a peer was connected within the window
+func (t *availabilityTracker) ratio(peerID string, window time.Duration, now time.Time) (float64, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	peer, ok := t.peers[peerID]
+	if !ok || len(peer.Tiers) != len(availabilityTiers) {
+		return 0, false
+	}
+
+	// Use the finest tier that still covers the whole window
+	tier := len(availabilityTiers) - 1
+	for i := range availabilityTiers {
+		if availabilityTiers[i].retention >= window {
+			tier = i
+			break
+		}
+	}
+	bucketSize := int64(availabilityTiers[tier].bucketSize.Seconds())
+
+	cutoff := now.Add(-window).Unix()
+	connected, observed := 0.0, 0.0
+	for _, bucket := range peer.Tiers[tier] {
+		if bucket.Start+bucketSize <= cutoff {
+			continue
+		}
+		connected += bucket.Connected
+		observed += bucket.Observed
+	}
+
+	if observed == 0 {
+		return 0, false
+	}
+	return connected / observed, true
+}
+
+// retain forgets peers that are not in the given set
+func (t *availabilityTracker) retain(peerIDs map[string]bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for peerID := range t.peers {
+		if !peerIDs[peerID] {
+			delete(t.peers, peerID)
+		}
+	}
+}
+
+// load restores the tracker state from a file; a missing file is not an error
+func (t *availabilityTracker) load(path string) error {
+	data, err := os.ReadFile(path) // #nosec G304 -- path is configured by the operator
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	peers := make(map[string]*peerAvailability)
+	if err := json.Unmarshal(data, &peers); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.peers = peers
+	return nil
+}
+
+// save writes the tracker state to a file if the persist interval has elapsed
+func (t *availabilityTracker) save(path string, now time.Time) error {
+	return t.write(path, now, false)
+}
+
+// flush writes the tracker state to a file regardless of the persist interval
+func (t *availabilityTracker) flush(path string) error {
+	return t.write(path, time.Now(), true)
+}
+
+// write encodes the tracker state and writes it to a file. Only encoding holds
+// the tracker lock; the file is written without it. A state older than the one
+// already written, e.g. from a background save overtaken by a flush, is skipped.
+func (t *availabilityTracker) write(path string, now time.Time, force bool) error {
+	t.mu.Lock()
+	if !force && now.Sub(t.lastPersisted) < availabilityPersistInterval {
+		t.mu.Unlock()
+		return nil
+	}
+	data, err := json.Marshal(t.peers)
+	if err == nil {
+		t.lastPersisted = now
+		t.encodedSeq++
+	}
+	seq := t.encodedSeq
+	t.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	if seq <= t.writtenSeq {
+		return nil
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated state file
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return err
+	}
+	t.writtenSeq = seq
+	return nil
+}
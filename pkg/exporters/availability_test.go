@@ -0,0 +1,186 @@
+package exporters
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAvailabilityTracker_Ratio(t *testing.T) {
+	tracker := newAvailabilityTracker()
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Connected for 30 minutes, then disconnected for 30 minutes, polled every minute
+	now := start
+	for i := 0; i <= 60; i++ {
+		tracker.record("peer1", i < 30, now)
+		now = now.Add(time.Minute)
+	}
+	now = now.Add(-time.Minute)
+
+	ratio, ok := tracker.ratio("peer1", time.Hour, now)
+	if !ok {
+		t.Fatal("Expected a ratio for peer1")
+	}
+	if math.Abs(ratio-0.5) > 0.001 {
+		t.Errorf("Expected 1h availability of 0.5, got %f", ratio)
+	}
+
+	if _, ok := tracker.ratio("unknown", time.Hour, now); ok {
+		t.Error("Expected no ratio for an unknown peer")
+	}
+}
+
+func TestAvailabilityTracker_IgnoresGaps(t *testing.T) {
+	tracker := newAvailabilityTracker()
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tracker.record("peer1", false, start)
+	tracker.record("peer1", true, start.Add(time.Minute))
+	// Exporter was down for two hours: the gap must not count as disconnected time
+	tracker.record("peer1", true, start.Add(2*time.Hour))
+	tracker.record("peer1", true, start.Add(2*time.Hour+time.Minute))
+
+	ratio, _ := tracker.ratio("peer1", 24*time.Hour, start.Add(2*time.Hour+time.Minute))
+	if math.Abs(ratio-0.5) > 0.001 {
+		t.Errorf("Expected availability of 0.5 ignoring the gap, got %f", ratio)
+	}
+}
+
+func TestAvailabilityTracker_RetentionAndRetain(t *testing.T) {
+	tracker := newAvailabilityTracker()
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tracker.record("peer1", true, start)
+	tracker.record("peer1", true, start.Add(time.Minute))
+	tracker.record("peer2", true, start)
+
+	later := start.Add(8 * 24 * time.Hour)
+	tracker.record("peer1", true, later)
+	for i, buckets := range tracker.peers["peer1"].Tiers {
+		if len(buckets) != 0 {
+			t.Errorf("Expected tier %d buckets older than the retention to be dropped, got %d", i, len(buckets))
+		}
+	}
+
+	tracker.retain(map[string]bool{"peer1": true})
+	if _, ok := tracker.peers["peer2"]; ok {
+		t.Error("Expected peer2 to be forgotten")
+	}
+}
+
+func TestAvailabilityTracker_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "availability.json")
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tracker := newAvailabilityTracker()
+	tracker.record("peer1", true, start)
+	tracker.record("peer1", true, start.Add(time.Minute))
+	if err := tracker.save(path, start.Add(time.Minute)); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+
+	restored := newAvailabilityTracker()
+	if err := restored.load(path); err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	ratio, ok := restored.ratio("peer1", time.Hour, start.Add(time.Minute))
+	if !ok || ratio != 1 {
+		t.Errorf("Expected restored availability of 1, got %f (ok=%v)", ratio, ok)
+	}
+
+	missing := newAvailabilityTracker()
+	if err := missing.load(filepath.Join(t.TempDir(), "missing.json")); err != nil {
+		t.Errorf("Expected a missing state file to be ignored, got %v", err)
+	}
+}
+
+func TestAvailabilityTracker_CompactWeekOfHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "availability.json")
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// A week of one-minute polls, connected every other hour
+	tracker := newAvailabilityTracker()
+	now := start
+	for now.Before(start.Add(7 * 24 * time.Hour)) {
+		tracker.record("peer1", now.Hour()%2 == 0, now)
+		now = now.Add(time.Minute)
+	}
+	now = now.Add(-time.Minute)
+
+	tiers := tracker.peers["peer1"].Tiers
+	if len(tiers[0]) > 13 || len(tiers[1]) > 169 {
+		t.Errorf("Expected at most 13 fine and 169 hourly buckets, got %d and %d", len(tiers[0]), len(tiers[1]))
+	}
+
+	// Long windows are served from hourly buckets
+	for _, window := range []time.Duration{24 * time.Hour, 7 * 24 * time.Hour} {
+		ratio, ok := tracker.ratio("peer1", window, now)
+		if !ok || math.Abs(ratio-0.5) > 0.02 {
+			t.Errorf("Expected %s availability of about 0.5, got %f (ok=%v)", window, ratio, ok)
+		}
+	}
+
+	if err := tracker.save(path, now); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat state file: %v", err)
+	}
+	if info.Size() > 8*1024 {
+		t.Errorf("Expected a week of history to take less than 8 KB per peer, got %d bytes", info.Size())
+	}
+}
+
+func TestAvailabilityTracker_SlowScrapeInterval(t *testing.T) {
+	tracker := newAvailabilityTracker()
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Scraped every 30 minutes, above the minimum gap limit
+	now := start
+	for i := 0; i < 6; i++ {
+		if _, ok := tracker.observeScrape(now); !ok {
+			t.Errorf("Expected scrape %d at the usual interval to be counted", i)
+		}
+		tracker.record("peer1", true, now)
+		now = now.Add(30 * time.Minute)
+	}
+	now = now.Add(-30 * time.Minute)
+
+	if ratio, ok := tracker.ratio("peer1", 24*time.Hour, now); !ok || ratio != 1 {
+		t.Errorf("Expected availability of 1 with a 30 minute scrape interval, got %f (ok=%v)", ratio, ok)
+	}
+
+	// A gap far above the usual interval is still ignored
+	if _, ok := tracker.observeScrape(now.Add(6 * time.Hour)); ok {
+		t.Error("Expected a 6 hour gap to exceed the gap limit")
+	}
+}
+
+func TestAvailabilityTracker_FlushSupersedesOlderSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "availability.json")
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tracker := newAvailabilityTracker()
+	tracker.record("peer1", true, start)
+	if err := tracker.save(path, start); err != nil {
+		t.Fatalf("Failed to save state: %v", err)
+	}
+
+	// Flushing ignores the persist interval
+	tracker.record("peer2", true, start.Add(time.Minute))
+	if err := tracker.flush(path); err != nil {
+		t.Fatalf("Failed to flush state: %v", err)
+	}
+
+	restored := newAvailabilityTracker()
+	if err := restored.load(path); err != nil {
+		t.Fatalf("Failed to load state: %v", err)
+	}
+	if _, ok := restored.peers["peer2"]; !ok {
+		t.Error("Expected the flushed state to include peer2")
+	}
+}
@@ -111,6 +111,11 @@ func (e *DNSExporter) Describe(ch chan<- *prometheus.Desc) {
 
 // Collect implements prometheus.Collector
 func (e *DNSExporter) Collect(ch chan<- prometheus.Metric) {
+	e.collect(ch, newAPISnapshot(e.client))
+}
+
+// collect updates and collects the DNS metrics from the objects of one scrape
+func (e *DNSExporter) collect(ch chan<- prometheus.Metric, snapshot *apiSnapshot) {
 	// Reset metrics before collecting new values
 	e.nameserverGroupsTotal.Reset()
 	e.nameserverGroupsEnabled.Reset()
@@ -124,7 +129,7 @@ func (e *DNSExporter) Collect(ch chan<- prometheus.Metric) {
 	ctx, cancelNS := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancelNS()
 	// Fetch nameserver groups
-	nameserverGroups, err := e.client.DNS.ListNameserverGroups(ctx)
+	nameserverGroups, err := snapshot.nameserverGroups.get(ctx)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to fetch nameserver groups")
 	} else {
@@ -135,7 +140,7 @@ func (e *DNSExporter) Collect(ch chan<- prometheus.Metric) {
 	defer cancelSettings()
 
 	// Fetch DNS settings
-	dnsSettings, err := e.client.DNS.GetSettings(ctx)
+	dnsSettings, err := snapshot.dnsSettings.get(ctx)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to fetch DNS settings")
 	} else {
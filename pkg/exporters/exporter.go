@@ -1,6 +1,7 @@
 package exporters
 
 import (
+	"context"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -28,18 +29,23 @@ type NetBirdExporter struct {
 
 // NewNetBirdExporter creates a new NetBird exporter with all sub-exporters
 func NewNetBirdExporter(baseURL, token string) *NetBirdExporter {
+	return NewNetBirdExporterWithOptions(baseURL, token, DefaultOptions())
+}
+
+// NewNetBirdExporterWithOptions creates a new NetBird exporter with all sub-exporters using the given options
+func NewNetBirdExporterWithOptions(baseURL, token string, opts Options) *NetBirdExporter {
 
 	client := nbclient.New(baseURL, token)
 
 	return &NetBirdExporter{
 		client:            client,
-		peersExporter:     NewPeersExporter(client),
+		peersExporter:     NewPeersExporterWithOptions(client, opts),
 		groupsExporter:    NewGroupsExporter(client),
-		usersExporter:     NewUsersExporter(client),
+		usersExporter:     NewUsersExporterWithOptions(client, opts),
 		dnsExporter:       NewDNSExporter(client),
 		networksExporter:  NewNetworksExporter(client),
-		setupKeysExporter: NewSetupKeysExporter(client),
-		policiesExporter:  NewPoliciesExporter(client),
+		setupKeysExporter: NewSetupKeysExporterWithOptions(client, opts),
+		policiesExporter:  NewPoliciesExporterWithOptions(client, opts),
 		routesExporter:    NewRoutesExporter(client),
 
 		scrapeDuration: prometheus.NewHistogram(
@@ -58,6 +64,12 @@ func NewNetBirdExporter(baseURL, token string) *NetBirdExporter {
 	}
 }
 
+// SaveState persists state kept across restarts, such as the peer availability
+// history. It is meant to be called on shutdown.
+func (e *NetBirdExporter) SaveState() error {
+	return e.peersExporter.SaveState()
+}
+
 // Describe implements prometheus.Collector
 func (e *NetBirdExporter) Describe(ch chan<- *prometheus.Desc) {
 	e.peersExporter.Describe(ch)
@@ -85,6 +97,12 @@ func (e *NetBirdExporter) Collect(ch chan<- prometheus.Metric) {
 
 	logrus.Debug("Starting NetBird metrics collection")
 
+	// Fetch the objects shared by several sub-exporters once per scrape
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	snapshot := newAPISnapshot(e.client)
+	snapshot.prefetch(ctx)
+
 	// Collect from all sub-exporters
 	func() {
 		defer func() {
@@ -94,7 +112,7 @@ func (e *NetBirdExporter) Collect(ch chan<- prometheus.Metric) {
 			}
 		}()
 		logrus.Debug("Starting peers collection")
-		e.peersExporter.Collect(ch)
+		e.peersExporter.collect(ch, snapshot)
 		logrus.Debug("Completed peers collection")
 	}()
 
@@ -106,7 +124,7 @@ func (e *NetBirdExporter) Collect(ch chan<- prometheus.Metric) {
 			}
 		}()
 		logrus.Debug("Starting groups collection")
-		e.groupsExporter.Collect(ch)
+		e.groupsExporter.collect(ch, snapshot)
 		logrus.Debug("Completed groups collection")
 	}()
 
@@ -118,7 +136,7 @@ func (e *NetBirdExporter) Collect(ch chan<- prometheus.Metric) {
 			}
 		}()
 		logrus.Debug("Starting users collection")
-		e.usersExporter.Collect(ch)
+		e.usersExporter.collect(ch, snapshot)
 		logrus.Debug("Completed users collection")
 	}()
 
@@ -130,7 +148,7 @@ func (e *NetBirdExporter) Collect(ch chan<- prometheus.Metric) {
 			}
 		}()
 		logrus.Debug("Starting DNS collection")
-		e.dnsExporter.Collect(ch)
+		e.dnsExporter.collect(ch, snapshot)
 		logrus.Debug("Completed DNS collection")
 	}()
 
@@ -154,7 +172,7 @@ func (e *NetBirdExporter) Collect(ch chan<- prometheus.Metric) {
 			}
 		}()
 		logrus.Debug("Starting setup keys collection")
-		e.setupKeysExporter.Collect(ch)
+		e.setupKeysExporter.collect(ch, snapshot)
 		logrus.Debug("Completed setup keys collection")
 	}()
 
@@ -166,7 +184,7 @@ func (e *NetBirdExporter) Collect(ch chan<- prometheus.Metric) {
 			}
 		}()
 		logrus.Debug("Starting policies collection")
-		e.policiesExporter.Collect(ch)
+		e.policiesExporter.collect(ch, snapshot)
 		logrus.Debug("Completed policies collection")
 	}()
 
@@ -178,7 +196,7 @@ func (e *NetBirdExporter) Collect(ch chan<- prometheus.Metric) {
 			}
 		}()
 		logrus.Debug("Starting routes collection")
-		e.routesExporter.Collect(ch)
+		e.routesExporter.collect(ch, snapshot)
 		logrus.Debug("Completed routes collection")
 	}()
 }
@@ -448,3 +448,40 @@ func TestNetBirdExporter_ScrapeMetrics(t *testing.T) {
 		t.Error("Expected to find scrape duration metric")
 	}
 }
+
+// gatherMetricValue gathers the collector and returns the value of the first
+// series of the named metric family carrying all of the given labels
+func gatherMetricValue(t *testing.T, collector prometheus.Collector, name string, labels map[string]string) (float64, bool) {
+	t.Helper()
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Failed to gather metrics: %v", err)
+	}
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			matched := 0
+			for _, label := range metric.GetLabel() {
+				if value, ok := labels[label.GetName()]; ok && value == label.GetValue() {
+					matched++
+				}
+			}
+			if matched != len(labels) {
+				continue
+			}
+			if metric.GetCounter() != nil {
+				return metric.GetCounter().GetValue(), true
+			}
+			return metric.GetGauge().GetValue(), true
+		}
+	}
+
+	return 0, false
+}
@@ -2,6 +2,7 @@ package exporters
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	nbclient "github.com/netbirdio/netbird/shared/management/client/rest"
@@ -20,6 +21,12 @@ type GroupsExporter struct {
 	groupResourcesCount  *prometheus.GaugeVec
 	groupInfo            *prometheus.GaugeVec
 	groupResourcesByType *prometheus.GaugeVec
+	groupPeersConnected  *prometheus.GaugeVec
+	groupPeersExpired    *prometheus.GaugeVec
+	groupReferences      *prometheus.GaugeVec
+	groupsByIssued       *prometheus.GaugeVec
+	groupPeersByIssued   *prometheus.GaugeVec
+	groupsOrphaned       *prometheus.GaugeVec
 	scrapeErrorsTotal    *prometheus.CounterVec
 	scrapeDuration       *prometheus.HistogramVec
 }
@@ -69,6 +76,54 @@ func NewGroupsExporter(client *nbclient.Client) *GroupsExporter {
 			[]string{"group_id", "group_name", "resource_type"},
 		),
 
+		groupPeersConnected: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_group_peers_connected",
+				Help: "Number of currently connected peers in each NetBird group",
+			},
+			[]string{"group_id", "group_name"},
+		),
+
+		groupPeersExpired: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_group_peers_login_expired",
+				Help: "Number of peers with an expired login in each NetBird group",
+			},
+			[]string{"group_id", "group_name"},
+		),
+
+		groupsByIssued: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_groups_by_issued",
+				Help: "Number of NetBird groups by how they were issued",
+			},
+			[]string{"issued"},
+		),
+
+		groupPeersByIssued: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_group_peers_by_issued",
+				Help: "Total number of peers in NetBird groups by how the groups were issued",
+			},
+			[]string{"issued"},
+		),
+
+		groupReferences: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_group_references",
+				Help: "Number of NetBird objects referencing each group by object type",
+			},
+			[]string{"group_id", "group_name", "referenced_by"},
+		),
+
+		groupsOrphaned: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_groups_orphaned",
+				Help: "Number of NetBird groups that are unreferenced or have no members",
+			},
+			[]string{"reason"},
+		),
+
 		scrapeErrorsTotal: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "netbird_groups_scrape_errors_total",
@@ -94,12 +149,23 @@ func (e *GroupsExporter) Describe(ch chan<- *prometheus.Desc) {
 	e.groupResourcesCount.Describe(ch)
 	e.groupInfo.Describe(ch)
 	e.groupResourcesByType.Describe(ch)
+	e.groupPeersConnected.Describe(ch)
+	e.groupPeersExpired.Describe(ch)
+	e.groupsByIssued.Describe(ch)
+	e.groupPeersByIssued.Describe(ch)
+	e.groupReferences.Describe(ch)
+	e.groupsOrphaned.Describe(ch)
 	e.scrapeErrorsTotal.Describe(ch)
 	e.scrapeDuration.Describe(ch)
 }
 
 // Collect implements prometheus.Collector
 func (e *GroupsExporter) Collect(ch chan<- prometheus.Metric) {
+	e.collect(ch, newAPISnapshot(e.client))
+}
+
+// collect updates and collects the group metrics from the objects of one scrape
+func (e *GroupsExporter) collect(ch chan<- prometheus.Metric, snapshot *apiSnapshot) {
 	timer := prometheus.NewTimer(e.scrapeDuration.WithLabelValues())
 	defer timer.ObserveDuration()
 
@@ -109,11 +175,17 @@ func (e *GroupsExporter) Collect(ch chan<- prometheus.Metric) {
 	e.groupResourcesCount.Reset()
 	e.groupInfo.Reset()
 	e.groupResourcesByType.Reset()
+	e.groupPeersConnected.Reset()
+	e.groupPeersExpired.Reset()
+	e.groupsByIssued.Reset()
+	e.groupPeersByIssued.Reset()
+	e.groupReferences.Reset()
+	e.groupsOrphaned.Reset()
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
 	defer cancel()
 
-	groups, err := e.client.Groups.List(ctx)
+	groups, err := snapshot.groups.get(ctx)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to fetch groups")
 		e.scrapeErrorsTotal.WithLabelValues("fetch_groups").Inc()
@@ -122,12 +194,39 @@ func (e *GroupsExporter) Collect(ch chan<- prometheus.Metric) {
 
 	e.updateMetrics(groups)
 
+	// Fetch group references while the peers are being fetched
+	var references *groupReferences
+	var complete bool
+	referencesDone := make(chan struct{})
+	go func() {
+		defer close(referencesDone)
+		references, complete = e.fetchGroupReferences(ctx, snapshot)
+	}()
+
+	peers, err := snapshot.peers.get(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch peers for group status metrics")
+		e.scrapeErrorsTotal.WithLabelValues("fetch_peers").Inc()
+	} else {
+		e.updatePeerStatusMetrics(groups, peers)
+	}
+
+	<-referencesDone
+	references.addNetworkResources(groups)
+	e.updateReferenceMetrics(groups, references, complete)
+
 	// Collect all metrics
 	e.groupsTotal.Collect(ch)
 	e.groupPeersCount.Collect(ch)
 	e.groupResourcesCount.Collect(ch)
 	e.groupInfo.Collect(ch)
 	e.groupResourcesByType.Collect(ch)
+	e.groupPeersConnected.Collect(ch)
+	e.groupPeersExpired.Collect(ch)
+	e.groupsByIssued.Collect(ch)
+	e.groupPeersByIssued.Collect(ch)
+	e.groupReferences.Collect(ch)
+	e.groupsOrphaned.Collect(ch)
 	e.scrapeErrorsTotal.Collect(ch)
 	e.scrapeDuration.Collect(ch)
 }
@@ -142,11 +241,25 @@ func (e *GroupsExporter) updateMetrics(groups []api.Group) {
 	totalResources := 0
 	resourceTypeTotals := make(map[string]int)
 
+	// Report every known issuer so a stopped IdP sync shows up as zero
+	groupsByIssued := map[string]int{
+		string(api.GroupIssuedApi):         0,
+		string(api.GroupIssuedJwt):         0,
+		string(api.GroupIssuedIntegration): 0,
+	}
+	peersByIssued := map[string]int{
+		string(api.GroupIssuedApi):         0,
+		string(api.GroupIssuedJwt):         0,
+		string(api.GroupIssuedIntegration): 0,
+	}
+
 	for _, group := range groups {
 		issued := ""
 		if group.Issued != nil {
 			issued = string(*group.Issued)
 		}
+		groupsByIssued[issued]++
+		peersByIssued[issued] += group.PeersCount
 		groupLabels := []string{group.Id, group.Name, issued}
 
 		// Set basic group metrics
@@ -176,11 +289,337 @@ func (e *GroupsExporter) updateMetrics(groups []api.Group) {
 
 	e.groupsTotal.WithLabelValues().Set(float64(totalGroups))
 
+	for issued, count := range groupsByIssued {
+		e.groupsByIssued.WithLabelValues(issued).Set(float64(count))
+		e.groupPeersByIssued.WithLabelValues(issued).Set(float64(peersByIssued[issued]))
+	}
+
 	logrus.WithFields(logrus.Fields{
 		"total_groups":          totalGroups,
 		"total_peers_in_groups": totalPeers,
 		"total_resources":       totalResources,
 		"resource_types":        len(resourceTypeTotals),
 		"resource_type_counts":  resourceTypeTotals,
+		"groups_by_issued":      groupsByIssued,
 	}).Debug("Updated group metrics")
 }
+
+// updatePeerStatusMetrics counts connected and login-expired peers per group
+// using the groups attached to each peer
+func (e *GroupsExporter) updatePeerStatusMetrics(groups []api.Group, peers []api.Peer) {
+	connectedCounts := make(map[string]int)
+	expiredCounts := make(map[string]int)
+
+	for _, peer := range peers {
+		for _, group := range peer.Groups {
+			if peer.Connected {
+				connectedCounts[group.Id]++
+			}
+			if peer.LoginExpired {
+				expiredCounts[group.Id]++
+			}
+		}
+	}
+
+	// Report every group, including those with no connected peers
+	for _, group := range groups {
+		e.groupPeersConnected.WithLabelValues(group.Id, group.Name).Set(float64(connectedCounts[group.Id]))
+		e.groupPeersExpired.WithLabelValues(group.Id, group.Name).Set(float64(expiredCounts[group.Id]))
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"groups_with_connected_peers": len(connectedCounts),
+		"groups_with_expired_peers":   len(expiredCounts),
+	}).Debug("Updated group peer status metrics")
+}
+
+// Object types that can reference a group
+const (
+	referencedByPolicy          = "policy"
+	referencedByRoute           = "route"
+	referencedByDNS             = "dns"
+	referencedBySetupKey        = "setup_key"
+	referencedByNetworkResource = "network_resource"
+	referencedByNetworkRouter   = "network_router"
+	referencedByUser            = "user"
+	referencedByDNSSettings     = "dns_settings"
+	referencedByAccount         = "account_settings"
+)
+
+// allGroupName is the built-in group every peer belongs to; it cannot be deleted
+const allGroupName = "All"
+
+// groupReferences counts referencing objects per group ID and object type
+type groupReferences struct {
+	counts  map[string]map[string]int
+	fetched map[string]bool
+}
+
+func newGroupReferences() *groupReferences {
+	return &groupReferences{
+		counts:  make(map[string]map[string]int),
+		fetched: make(map[string]bool),
+	}
+}
+
+// add records a reference from an object of the given type to a group
+func (r *groupReferences) add(groupID, referencedBy string) {
+	if r.counts[groupID] == nil {
+		r.counts[groupID] = make(map[string]int)
+	}
+	r.counts[groupID][referencedBy]++
+}
+
+// addUnique records one reference per distinct group in the list, so an object
+// listing the same group twice is only counted once
+func (r *groupReferences) addUnique(groupIDs []string, referencedBy string) {
+	seen := make(map[string]bool, len(groupIDs))
+	for _, groupID := range groupIDs {
+		if groupID == "" || seen[groupID] {
+			continue
+		}
+		seen[groupID] = true
+		r.add(groupID, referencedBy)
+	}
+}
+
+func (r *groupReferences) addPolicies(policies []api.Policy) {
+	r.fetched[referencedByPolicy] = true
+	for _, policy := range policies {
+		var groupIDs []string
+		for _, rule := range policy.Rules {
+			groupIDs = append(groupIDs, groupMinimumIDs(rule.Sources)...)
+			groupIDs = append(groupIDs, groupMinimumIDs(rule.Destinations)...)
+		}
+		r.addUnique(groupIDs, referencedByPolicy)
+	}
+}
+
+func (r *groupReferences) addRoutes(routes []api.Route) {
+	r.fetched[referencedByRoute] = true
+	for _, route := range routes {
+		groupIDs := append([]string{}, route.Groups...)
+		if route.PeerGroups != nil {
+			groupIDs = append(groupIDs, *route.PeerGroups...)
+		}
+		if route.AccessControlGroups != nil {
+			groupIDs = append(groupIDs, *route.AccessControlGroups...)
+		}
+		r.addUnique(groupIDs, referencedByRoute)
+	}
+}
+
+func (r *groupReferences) addNameserverGroups(nameserverGroups []api.NameserverGroup) {
+	r.fetched[referencedByDNS] = true
+	for _, nameserverGroup := range nameserverGroups {
+		r.addUnique(nameserverGroup.Groups, referencedByDNS)
+	}
+}
+
+func (r *groupReferences) addSetupKeys(setupKeys []api.SetupKey) {
+	r.fetched[referencedBySetupKey] = true
+	for _, setupKey := range setupKeys {
+		r.addUnique(setupKey.AutoGroups, referencedBySetupKey)
+	}
+}
+
+// addNetworkResources counts the network resources assigned to each group, which
+// the groups API already embeds so no per-network requests are needed
+func (r *groupReferences) addNetworkResources(groups []api.Group) {
+	r.fetched[referencedByNetworkResource] = true
+	for _, group := range groups {
+		for _, resource := range group.Resources {
+			if resource.Id != "" {
+				r.add(group.Id, referencedByNetworkResource)
+			}
+		}
+	}
+}
+
+func (r *groupReferences) addNetworkRouters(routers []api.NetworkRouter) {
+	r.fetched[referencedByNetworkRouter] = true
+	for _, router := range routers {
+		if router.PeerGroups != nil {
+			r.addUnique(*router.PeerGroups, referencedByNetworkRouter)
+		}
+	}
+}
+
+// addUsers records the groups assigned to peers registered by each user, which
+// is how groups synced from an IdP are usually consumed
+func (r *groupReferences) addUsers(users []api.User) {
+	r.fetched[referencedByUser] = true
+	for _, user := range users {
+		r.addUnique(user.AutoGroups, referencedByUser)
+	}
+}
+
+func (r *groupReferences) addDNSSettings(settings *api.DNSSettings) {
+	r.fetched[referencedByDNSSettings] = true
+	if settings != nil {
+		r.addUnique(settings.DisabledManagementGroups, referencedByDNSSettings)
+	}
+}
+
+// addAccountSettings records the groups used by account settings. JWT allow
+// groups hold group names from the IdP claim, so they are resolved by name.
+func (r *groupReferences) addAccountSettings(accounts []api.Account, groups []api.Group) {
+	r.fetched[referencedByAccount] = true
+
+	groupIDsByName := make(map[string]string, len(groups))
+	for _, group := range groups {
+		groupIDsByName[group.Name] = group.Id
+	}
+
+	for _, account := range accounts {
+		settings := account.Settings
+		groupIDs := append([]string{}, settings.PeerExposeGroups...)
+		if settings.Ipv6EnabledGroups != nil {
+			groupIDs = append(groupIDs, *settings.Ipv6EnabledGroups...)
+		}
+		if settings.JwtAllowGroups != nil {
+			for _, name := range *settings.JwtAllowGroups {
+				if groupID, ok := groupIDsByName[name]; ok {
+					groupIDs = append(groupIDs, groupID)
+				} else {
+					groupIDs = append(groupIDs, name)
+				}
+			}
+		}
+		r.addUnique(groupIDs, referencedByAccount)
+	}
+}
+
+// groupMinimumIDs returns the IDs of an optional list of groups
+func groupMinimumIDs(groups *[]api.GroupMinimum) []string {
+	if groups == nil {
+		return nil
+	}
+	ids := make([]string, 0, len(*groups))
+	for _, group := range *groups {
+		ids = append(ids, group.Id)
+	}
+	return ids
+}
+
+// groupReferenceSource reads one kind of object that can reference groups and
+// returns a function recording its references
+type groupReferenceSource struct {
+	errorType string
+	fetch     func(ctx context.Context) (func(*groupReferences), error)
+}
+
+// groupReferenceSources lists every object type that can point at a group
+func groupReferenceSources(snapshot *apiSnapshot) []groupReferenceSource {
+	return []groupReferenceSource{
+		{"fetch_policies", func(ctx context.Context) (func(*groupReferences), error) {
+			policies, err := snapshot.policies.get(ctx)
+			return func(r *groupReferences) { r.addPolicies(policies) }, err
+		}},
+		{"fetch_routes", func(ctx context.Context) (func(*groupReferences), error) {
+			routes, err := snapshot.routes.get(ctx)
+			return func(r *groupReferences) { r.addRoutes(routes) }, err
+		}},
+		{"fetch_nameserver_groups", func(ctx context.Context) (func(*groupReferences), error) {
+			nameserverGroups, err := snapshot.nameserverGroups.get(ctx)
+			return func(r *groupReferences) { r.addNameserverGroups(nameserverGroups) }, err
+		}},
+		{"fetch_setup_keys", func(ctx context.Context) (func(*groupReferences), error) {
+			setupKeys, err := snapshot.setupKeys.get(ctx)
+			return func(r *groupReferences) { r.addSetupKeys(setupKeys) }, err
+		}},
+		{"fetch_network_routers", func(ctx context.Context) (func(*groupReferences), error) {
+			routers, err := snapshot.networkRouters.get(ctx)
+			return func(r *groupReferences) { r.addNetworkRouters(routers) }, err
+		}},
+		{"fetch_users", func(ctx context.Context) (func(*groupReferences), error) {
+			users, err := snapshot.users.get(ctx)
+			return func(r *groupReferences) { r.addUsers(users) }, err
+		}},
+		{"fetch_dns_settings", func(ctx context.Context) (func(*groupReferences), error) {
+			settings, err := snapshot.dnsSettings.get(ctx)
+			return func(r *groupReferences) { r.addDNSSettings(settings) }, err
+		}},
+		{"fetch_accounts", func(ctx context.Context) (func(*groupReferences), error) {
+			accounts, err := snapshot.accounts.get(ctx)
+			if err != nil {
+				return nil, err
+			}
+			groups, err := snapshot.groups.get(ctx)
+			return func(r *groupReferences) { r.addAccountSettings(accounts, groups) }, err
+		}},
+	}
+}
+
+// fetchGroupReferences collects group references from all sources concurrently,
+// since objects missing from the snapshot are separate API calls. The returned
+// flag is false if any source could not be fetched.
+func (e *GroupsExporter) fetchGroupReferences(ctx context.Context, snapshot *apiSnapshot) (*groupReferences, bool) {
+	sources := groupReferenceSources(snapshot)
+	results := make([]func(*groupReferences), len(sources))
+	errs := make([]error, len(sources))
+
+	var wg sync.WaitGroup
+	for i, source := range sources {
+		wg.Add(1)
+		go func(i int, source groupReferenceSource) {
+			defer wg.Done()
+			results[i], errs[i] = source.fetch(ctx)
+		}(i, source)
+	}
+	wg.Wait()
+
+	references := newGroupReferences()
+	complete := true
+	for i, source := range sources {
+		if errs[i] != nil {
+			logrus.WithError(errs[i]).WithField("source", source.errorType).Error("Failed to fetch group references")
+			e.scrapeErrorsTotal.WithLabelValues(source.errorType).Inc()
+			complete = false
+			continue
+		}
+		results[i](references)
+	}
+
+	return references, complete
+}
+
+// updateReferenceMetrics exports reference counts per group and counts orphaned
+// groups. Groups are only reported as unreferenced when every source was fetched,
+// so a failing API call never makes a group in use look safe to delete.
+func (e *GroupsExporter) updateReferenceMetrics(groups []api.Group, references *groupReferences, complete bool) {
+	unreferenced := 0
+	empty := 0
+
+	for _, group := range groups {
+		total := 0
+		for referencedBy := range references.fetched {
+			count := references.counts[group.Id][referencedBy]
+			e.groupReferences.WithLabelValues(group.Id, group.Name, referencedBy).Set(float64(count))
+			total += count
+		}
+
+		// The built-in "All" group cannot be deleted, so it is never orphaned
+		if group.Name == allGroupName {
+			continue
+		}
+		if total == 0 {
+			unreferenced++
+		}
+		if group.PeersCount == 0 && group.ResourcesCount == 0 {
+			empty++
+		}
+	}
+
+	if complete {
+		e.groupsOrphaned.WithLabelValues("unreferenced").Set(float64(unreferenced))
+	}
+	e.groupsOrphaned.WithLabelValues("empty").Set(float64(empty))
+
+	logrus.WithFields(logrus.Fields{
+		"reference_sources":   len(references.fetched),
+		"references_complete": complete,
+		"unreferenced_groups": unreferenced,
+		"empty_groups":        empty,
+	}).Debug("Updated group reference metrics")
+}
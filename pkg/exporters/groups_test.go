@@ -395,3 +395,161 @@ func TestGroupsExporter_MetricLabels(t *testing.T) {
 		t.Error("Expected to find metrics with labels")
 	}
 }
+
+func TestGroupsExporter_PeerStatus(t *testing.T) {
+	client := nbclient.New("https://api.netbird.io", "test-token")
+	exporter := NewGroupsExporter(client)
+
+	groups := []api.Group{
+		{Id: "routers", Name: "Routers", PeersCount: 2},
+		{Id: "laptops", Name: "Laptops", PeersCount: 2},
+	}
+	peers := []api.Peer{
+		{Id: "peer1", Connected: false, Groups: []api.GroupMinimum{{Id: "routers", Name: "Routers"}}},
+		{Id: "peer2", Connected: false, LoginExpired: true, Groups: []api.GroupMinimum{{Id: "routers", Name: "Routers"}}},
+		{Id: "peer3", Connected: true, Groups: []api.GroupMinimum{{Id: "laptops", Name: "Laptops"}}},
+		{Id: "peer4", Connected: true, LoginExpired: true, Groups: []api.GroupMinimum{{Id: "laptops", Name: "Laptops"}}},
+	}
+
+	exporter.updatePeerStatusMetrics(groups, peers)
+
+	tests := []struct {
+		collector prometheus.Collector
+		name      string
+		groupID   string
+		expected  float64
+	}{
+		{exporter.groupPeersConnected, "netbird_group_peers_connected", "routers", 0},
+		{exporter.groupPeersConnected, "netbird_group_peers_connected", "laptops", 2},
+		{exporter.groupPeersExpired, "netbird_group_peers_login_expired", "routers", 1},
+		{exporter.groupPeersExpired, "netbird_group_peers_login_expired", "laptops", 1},
+	}
+	for _, tt := range tests {
+		value, found := gatherMetricValue(t, tt.collector, tt.name, map[string]string{"group_id": tt.groupID})
+		if !found || value != tt.expected {
+			t.Errorf("Expected %s for %s to be %f, got %f (found=%v)", tt.name, tt.groupID, tt.expected, value, found)
+		}
+	}
+}
+
+func TestGroupsExporter_References(t *testing.T) {
+	client := nbclient.New("https://api.netbird.io", "test-token")
+	exporter := NewGroupsExporter(client)
+
+	groups := []api.Group{
+		{Id: "all", Name: "All", PeersCount: 3},
+		{Id: "devs", Name: "Devs", PeersCount: 2},
+		{Id: "servers", Name: "Servers", PeersCount: 1},
+		{Id: "stale", Name: "Stale"},
+		{Id: "unused", Name: "Unused", PeersCount: 1},
+		{Id: "idp-synced", Name: "Engineering", PeersCount: 4},
+		{Id: "no-dns", Name: "No DNS", PeersCount: 1},
+		{Id: "contractors", Name: "Contractors", PeersCount: 1},
+	}
+
+	routeGroups := []string{"servers"}
+	references := newGroupReferences()
+	references.addPolicies([]api.Policy{{
+		Name: "devs-to-servers",
+		Rules: []api.PolicyRule{
+			{
+				Sources:      &[]api.GroupMinimum{{Id: "devs"}},
+				Destinations: &[]api.GroupMinimum{{Id: "servers"}},
+			},
+			{
+				Sources:      &[]api.GroupMinimum{{Id: "devs"}},
+				Destinations: &[]api.GroupMinimum{{Id: "devs"}},
+			},
+		},
+	}})
+	references.addRoutes([]api.Route{{Groups: []string{"all"}, PeerGroups: &routeGroups}})
+	references.addNameserverGroups([]api.NameserverGroup{{Groups: []string{"all"}}})
+	references.addSetupKeys([]api.SetupKey{{AutoGroups: []string{"devs", "stale"}}})
+	references.addNetworkRouters([]api.NetworkRouter{{PeerGroups: &routeGroups}})
+	references.addNetworkResources([]api.Group{{Id: "servers", Resources: []api.Resource{{Id: "resource1", Type: "host"}}}})
+	references.addUsers([]api.User{{AutoGroups: []string{"idp-synced"}}})
+	references.addDNSSettings(&api.DNSSettings{DisabledManagementGroups: []string{"no-dns"}})
+	jwtAllowGroups := []string{"Contractors"}
+	references.addAccountSettings([]api.Account{{Settings: api.AccountSettings{JwtAllowGroups: &jwtAllowGroups}}}, groups)
+
+	exporter.updateReferenceMetrics(groups, references, true)
+
+	referenceTests := []struct {
+		groupID      string
+		referencedBy string
+		expected     float64
+	}{
+		{"devs", referencedByPolicy, 1},
+		{"servers", referencedByPolicy, 1},
+		{"servers", referencedByRoute, 1},
+		{"servers", referencedByNetworkRouter, 1},
+		{"servers", referencedByNetworkResource, 1},
+		{"all", referencedByDNS, 1},
+		{"stale", referencedBySetupKey, 1},
+		{"idp-synced", referencedByUser, 1},
+		{"no-dns", referencedByDNSSettings, 1},
+		{"contractors", referencedByAccount, 1},
+		{"unused", referencedByPolicy, 0},
+	}
+	for _, tt := range referenceTests {
+		value, found := gatherMetricValue(t, exporter.groupReferences, "netbird_group_references",
+			map[string]string{"group_id": tt.groupID, "referenced_by": tt.referencedBy})
+		if !found || value != tt.expected {
+			t.Errorf("Expected %s references from %s to be %f, got %f (found=%v)", tt.groupID, tt.referencedBy, tt.expected, value, found)
+		}
+	}
+
+	orphanTests := []struct {
+		reason   string
+		expected float64
+	}{
+		{"unreferenced", 1},
+		{"empty", 1},
+	}
+	for _, tt := range orphanTests {
+		value, found := gatherMetricValue(t, exporter.groupsOrphaned, "netbird_groups_orphaned", map[string]string{"reason": tt.reason})
+		if !found || value != tt.expected {
+			t.Errorf("Expected %s orphaned groups to be %f, got %f (found=%v)", tt.reason, tt.expected, value, found)
+		}
+	}
+
+	// Incomplete reference data must not report groups as unreferenced
+	exporter.groupsOrphaned.Reset()
+	exporter.updateReferenceMetrics(groups, newGroupReferences(), false)
+	if _, found := gatherMetricValue(t, exporter.groupsOrphaned, "netbird_groups_orphaned", map[string]string{"reason": "unreferenced"}); found {
+		t.Error("Expected no unreferenced count when references are incomplete")
+	}
+}
+
+func TestGroupsExporter_ByIssued(t *testing.T) {
+	client := nbclient.New("https://api.netbird.io", "test-token")
+	exporter := NewGroupsExporter(client)
+
+	issuedAPI := api.GroupIssuedApi
+	issuedJWT := api.GroupIssuedJwt
+	exporter.updateMetrics([]api.Group{
+		{Id: "group1", Name: "All", PeersCount: 6, Issued: &issuedAPI},
+		{Id: "group2", Name: "Engineering", PeersCount: 4, Issued: &issuedJWT},
+		{Id: "group3", Name: "Sales", PeersCount: 2, Issued: &issuedJWT},
+	})
+
+	tests := []struct {
+		collector prometheus.Collector
+		name      string
+		issued    string
+		expected  float64
+	}{
+		{exporter.groupsByIssued, "netbird_groups_by_issued", "api", 1},
+		{exporter.groupsByIssued, "netbird_groups_by_issued", "jwt", 2},
+		{exporter.groupsByIssued, "netbird_groups_by_issued", "integration", 0},
+		{exporter.groupPeersByIssued, "netbird_group_peers_by_issued", "api", 6},
+		{exporter.groupPeersByIssued, "netbird_group_peers_by_issued", "jwt", 6},
+		{exporter.groupPeersByIssued, "netbird_group_peers_by_issued", "integration", 0},
+	}
+	for _, tt := range tests {
+		value, found := gatherMetricValue(t, tt.collector, tt.name, map[string]string{"issued": tt.issued})
+		if !found || value != tt.expected {
+			t.Errorf("Expected %s{issued=%q} to be %f, got %f (found=%v)", tt.name, tt.issued, tt.expected, value, found)
+		}
+	}
+}
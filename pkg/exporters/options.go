@@ -0,0 +1,160 @@
+package exporters
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GeoGranularity controls how finely peers are aggregated by location
+type GeoGranularity string
+
+const (
+	// GeoGranularityNone disables the geographic peer distribution
+	GeoGranularityNone GeoGranularity = "none"
+	// GeoGranularityCountry aggregates peers by country only
+	GeoGranularityCountry GeoGranularity = "country"
+	// GeoGranularityCity aggregates peers by country and city
+	GeoGranularityCity GeoGranularity = "city"
+)
+
+// ParseGeoGranularity parses a geo granularity name
+func ParseGeoGranularity(value string) (GeoGranularity, error) {
+	switch granularity := GeoGranularity(value); granularity {
+	case GeoGranularityNone, GeoGranularityCountry, GeoGranularityCity:
+		return granularity, nil
+	default:
+		return "", fmt.Errorf("invalid geo granularity %q: must be one of none, country, city", value)
+	}
+}
+
+// Period is a named duration used as a metric label, such as "90d"
+type Period struct {
+	Label    string
+	Duration time.Duration
+}
+
+// ParsePeriods parses durations such as "30d", "2w" or "36h"; the original text
+// is kept as the label
+func ParsePeriods(values []string) ([]Period, error) {
+	periods := make([]Period, 0, len(values))
+	for _, value := range values {
+		duration, err := parsePeriodDuration(value)
+		if err != nil {
+			return nil, err
+		}
+		periods = append(periods, Period{Label: value, Duration: duration})
+	}
+	return periods, nil
+}
+
+// parsePeriodDuration extends time.ParseDuration with day and week units
+func parsePeriodDuration(value string) (time.Duration, error) {
+	units := map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour}
+	for suffix, unit := range units {
+		if number, ok := strings.CutSuffix(value, suffix); ok {
+			count, err := strconv.Atoi(number)
+			if err != nil || count <= 0 {
+				return 0, fmt.Errorf("invalid period %q", value)
+			}
+			return time.Duration(count) * unit, nil
+		}
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("invalid period %q", value)
+	}
+	return duration, nil
+}
+
+// ParsePorts parses TCP or UDP port numbers
+func ParsePorts(values []string) ([]int, error) {
+	ports := make([]int, 0, len(values))
+	for _, value := range values {
+		port, err := strconv.Atoi(value)
+		if err != nil || port < 1 || port > 65535 {
+			return nil, fmt.Errorf("invalid port %q", value)
+		}
+		ports = append(ports, port)
+	}
+	return ports, nil
+}
+
+// Options configures optional collectors of the NetBird exporters
+type Options struct {
+	// PeerSystemInfo exposes per-peer hardware and system details as an info series
+	PeerSystemInfo bool
+
+	// ExcludeEphemeralPeers omits ephemeral peers from per-peer series; aggregate counts still include them
+	ExcludeEphemeralPeers bool
+
+	// PeerGeoGranularity sets the location detail of netbird_peers_by_country
+	PeerGeoGranularity GeoGranularity
+
+	// PeerGeoNameID adds the GeoNames ID of the city to netbird_peers_by_country
+	PeerGeoNameID bool
+
+	// UserPeersThreshold is the peer count above which a user is reported by netbird_users_peers_above_threshold
+	UserPeersThreshold int
+
+	// PeerAvailability keeps a rolling connection history per peer for netbird_peer_availability_ratio
+	PeerAvailability bool
+
+	// PeerAvailabilityStateFile persists the availability history across restarts when set
+	PeerAvailabilityStateFile string
+
+	// OSFamilyMapping maps case-insensitive substrings of peer OS strings to an OS family,
+	// taking precedence over the built-in classification
+	OSFamilyMapping map[string]string
+
+	// PeerGroupOverlap lists group names or IDs whose pairwise peer overlap is exported
+	// by netbird_peer_group_overlap; the matrix is disabled when empty
+	PeerGroupOverlap []string
+
+	// UserTokens lists the personal access tokens of service users and of the
+	// exporter's own user, one API request per such user
+	UserTokens bool
+
+	// UserInactivePeriods are the thresholds reported by netbird_users_inactive
+	UserInactivePeriods []Period
+
+	// UserPermissionsPerUser exports the per-user netbird_user_permissions matrix for all modules
+	UserPermissionsPerUser bool
+
+	// UserPermissionsModules are modules exported per user even when the matrix is disabled
+	UserPermissionsModules []string
+
+	// SetupKeyLeakThreshold is the number of enrollments within an hour above which a
+	// reusable setup key is flagged by netbird_setup_key_usage_spike
+	SetupKeyLeakThreshold int
+
+	// PolicyRuleChecks are the checks reported by netbird_policy_rule_findings
+	PolicyRuleChecks []PolicyRuleCheck
+
+	// PolicySensitivePorts are the ports reported by netbird_policy_rules_by_port
+	PolicySensitivePorts []int
+
+	// PolicyWidePortRange is the number of consecutive ports above which a rule is
+	// flagged by netbird_policy_rule_wide_port_range
+	PolicyWidePortRange int
+}
+
+// DefaultOptions returns the options used when none are configured
+func DefaultOptions() Options {
+	return Options{
+		PeerGeoGranularity:     GeoGranularityCity,
+		UserPeersThreshold:     5,
+		UserPermissionsPerUser: true,
+		SetupKeyLeakThreshold:  10,
+		PolicyRuleChecks:       DefaultPolicyRuleChecks(),
+		PolicySensitivePorts:   []int{22, 23, 445, 1433, 3306, 3389, 5432, 5900, 6379, 27017},
+		PolicyWidePortRange:    1000,
+		UserInactivePeriods: []Period{
+			{Label: "30d", Duration: 30 * 24 * time.Hour},
+			{Label: "90d", Duration: 90 * 24 * time.Hour},
+			{Label: "180d", Duration: 180 * 24 * time.Hour},
+		},
+	}
+}
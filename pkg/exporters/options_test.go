@@ -0,0 +1,89 @@
+package exporters
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseGeoGranularity(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected GeoGranularity
+		wantErr  bool
+	}{
+		{value: "none", expected: GeoGranularityNone},
+		{value: "country", expected: GeoGranularityCountry},
+		{value: "city", expected: GeoGranularityCity},
+		{value: "region", wantErr: true},
+		{value: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			granularity, err := ParseGeoGranularity(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseGeoGranularity(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if granularity != tt.expected {
+				t.Errorf("ParseGeoGranularity(%q) = %q, want %q", tt.value, granularity, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParsePeriods(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Duration
+		wantErr  bool
+	}{
+		{value: "30d", expected: 30 * 24 * time.Hour},
+		{value: "2w", expected: 14 * 24 * time.Hour},
+		{value: "36h", expected: 36 * time.Hour},
+		{value: "0d", wantErr: true},
+		{value: "xd", wantErr: true},
+		{value: "soon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			periods, err := ParsePeriods([]string{tt.value})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePeriods(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if periods[0].Duration != tt.expected || periods[0].Label != tt.value {
+				t.Errorf("ParsePeriods(%q) = %+v, want duration %v", tt.value, periods[0], tt.expected)
+			}
+		})
+	}
+}
+
+func TestDefaultOptions(t *testing.T) {
+	opts := DefaultOptions()
+
+	if opts.PeerGeoGranularity != GeoGranularityCity {
+		t.Errorf("Expected default geo granularity to be city, got %q", opts.PeerGeoGranularity)
+	}
+	if opts.PeerSystemInfo {
+		t.Error("Expected peer system info to be disabled by default")
+	}
+}
+
+func TestParsePorts(t *testing.T) {
+	ports, err := ParsePorts([]string{"22", "3389"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(ports) != 2 || ports[0] != 22 || ports[1] != 3389 {
+		t.Errorf("Unexpected ports: %v", ports)
+	}
+
+	for _, value := range []string{"ssh", "0", "65536"} {
+		if _, err := ParsePorts([]string{value}); err == nil {
+			t.Errorf("Expected error for port %q", value)
+		}
+	}
+}
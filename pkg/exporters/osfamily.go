@@ -0,0 +1,117 @@
+package exporters
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// osFamilyRules are the built-in patterns used to classify peer operating systems,
+// checked in order so that more specific families win
+var osFamilyRules = []struct {
+	family  string
+	pattern *regexp.Regexp
+}{
+	{"windows", regexp.MustCompile(`(?i)windows`)},
+	{"android", regexp.MustCompile(`(?i)android`)},
+	{"ios", regexp.MustCompile(`(?i)\b(ios|ipados)\b`)},
+	{"darwin", regexp.MustCompile(`(?i)darwin|mac\s?os|os x`)},
+	{"freebsd", regexp.MustCompile(`(?i)freebsd`)},
+	{"linux", regexp.MustCompile(`(?i)linux|ubuntu|debian|fedora|centos|red hat|rhel|rocky|almalinux|alpine|\barch\b|suse|amazon|nixos|gentoo|manjaro|mint|raspbian|kali|openwrt`)},
+}
+
+var (
+	osVersionPattern    = regexp.MustCompile(`(\d+)(?:\.(\d+))?`)
+	windowsBuildPattern = regexp.MustCompile(`\b10\.0\.(\d+)`)
+	darwinKernelPattern = regexp.MustCompile(`(?i)^darwin\s+(\d+)`)
+)
+
+const (
+	// windows11FirstBuild is the first Windows build number released as Windows 11
+	windows11FirstBuild = 22000
+	// darwinToMacOSOffset converts a Darwin kernel major version to the macOS major version
+	darwinToMacOSOffset = 9
+)
+
+// osMappingRule maps raw OS strings containing a substring to a family
+type osMappingRule struct {
+	substring string
+	family    string
+}
+
+// osNormalizer turns raw peer OS strings into a family and a major.minor version
+type osNormalizer struct {
+	mapping []osMappingRule
+}
+
+// newOSNormalizer creates a normalizer that checks the configured substring
+// mapping (case-insensitive, longest substring first) before the built-in rules
+func newOSNormalizer(mapping map[string]string) *osNormalizer {
+	rules := make([]osMappingRule, 0, len(mapping))
+	for substring, family := range mapping {
+		rules = append(rules, osMappingRule{substring: strings.ToLower(substring), family: family})
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if len(rules[i].substring) != len(rules[j].substring) {
+			return len(rules[i].substring) > len(rules[j].substring)
+		}
+		return rules[i].substring < rules[j].substring
+	})
+
+	return &osNormalizer{mapping: rules}
+}
+
+// normalize returns the family and version of a raw OS string
+func (n *osNormalizer) normalize(rawOS string) (family, version string) {
+	rawOS = strings.TrimSpace(rawOS)
+	if rawOS == "" {
+		return "unknown", "unknown"
+	}
+
+	return n.family(rawOS), osVersion(rawOS)
+}
+
+// family classifies a raw OS string, falling back to "other"
+func (n *osNormalizer) family(rawOS string) string {
+	lower := strings.ToLower(rawOS)
+	for _, rule := range n.mapping {
+		if strings.Contains(lower, rule.substring) {
+			return rule.family
+		}
+	}
+
+	for _, rule := range osFamilyRules {
+		if rule.pattern.MatchString(rawOS) {
+			return rule.family
+		}
+	}
+	return "other"
+}
+
+// osVersion extracts a major.minor version so patch releases share a series
+func osVersion(rawOS string) string {
+	// Windows reports 10.0.<build> for both Windows 10 and 11
+	if match := windowsBuildPattern.FindStringSubmatch(rawOS); match != nil {
+		if build, err := strconv.Atoi(match[1]); err == nil && build >= windows11FirstBuild {
+			return "11"
+		}
+		return "10"
+	}
+
+	// Darwin kernel versions map to macOS releases from Darwin 20 (macOS 11) onwards
+	if match := darwinKernelPattern.FindStringSubmatch(rawOS); match != nil {
+		if major, err := strconv.Atoi(match[1]); err == nil && major >= 20 {
+			return strconv.Itoa(major - darwinToMacOSOffset)
+		}
+	}
+
+	match := osVersionPattern.FindStringSubmatch(rawOS)
+	if match == nil {
+		return "unknown"
+	}
+	if match[2] == "" {
+		return match[1]
+	}
+	return match[1] + "." + match[2]
+}
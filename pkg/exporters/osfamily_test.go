@@ -0,0 +1,61 @@
+package exporters
+
+import "testing"
+
+func TestOSNormalizer_Normalize(t *testing.T) {
+	normalizer := newOSNormalizer(nil)
+
+	tests := []struct {
+		rawOS   string
+		family  string
+		version string
+	}{
+		{"Ubuntu 22.04.3 LTS", "linux", "22.04"},
+		{"Ubuntu 22.04.4 LTS", "linux", "22.04"},
+		{"Debian GNU/Linux 12 (bookworm)", "linux", "12"},
+		{"linux", "linux", "unknown"},
+		{"Microsoft Windows 11 Pro", "windows", "11"},
+		{"Windows 10.0.22631", "windows", "11"},
+		{"Windows 10.0.19045", "windows", "10"},
+		{"Darwin 23.2.0", "darwin", "14"},
+		{"macOS 14.2.1", "darwin", "14.2"},
+		{"iOS 17.1.2", "ios", "17.1"},
+		{"Android 14", "android", "14"},
+		{"FreeBSD 14.0-RELEASE", "freebsd", "14.0"},
+		{"Haiku R1", "other", "1"},
+		{"", "unknown", "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rawOS, func(t *testing.T) {
+			family, version := normalizer.normalize(tt.rawOS)
+			if family != tt.family || version != tt.version {
+				t.Errorf("normalize(%q) = (%q, %q), want (%q, %q)", tt.rawOS, family, version, tt.family, tt.version)
+			}
+		})
+	}
+}
+
+func TestOSNormalizer_CustomMapping(t *testing.T) {
+	normalizer := newOSNormalizer(map[string]string{
+		"haiku":          "haiku",
+		"ubuntu core":    "embedded",
+		"UBUNTU CORE 22": "embedded-22",
+	})
+
+	tests := []struct {
+		rawOS  string
+		family string
+	}{
+		{"Haiku R1", "haiku"},
+		{"Ubuntu Core 22", "embedded-22"},
+		{"Ubuntu Core 20", "embedded"},
+		{"Ubuntu 22.04", "linux"},
+	}
+
+	for _, tt := range tests {
+		if family, _ := normalizer.normalize(tt.rawOS); family != tt.family {
+			t.Errorf("normalize(%q) family = %q, want %q", tt.rawOS, family, tt.family)
+		}
+	}
+}
@@ -3,7 +3,9 @@ package exporters
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	nbclient "github.com/netbirdio/netbird/shared/management/client/rest"
@@ -15,6 +17,7 @@ import (
 // PeersExporter handles peers-specific metrics collection
 type PeersExporter struct {
 	client *nbclient.Client
+	opts   Options
 
 	// Prometheus metrics
 	peersTotal                 *prometheus.GaugeVec
@@ -28,12 +31,73 @@ type PeersExporter struct {
 	peersApprovalRequired      *prometheus.GaugeVec
 	accessiblePeersCount       *prometheus.GaugeVec
 	peerConnectionStatusByName *prometheus.GaugeVec
+	peerConnectionTransitions  *prometheus.CounterVec
+	peerConnectionStateAge     *prometheus.GaugeVec
+	peersByKernelVersion       *prometheus.GaugeVec
+	peerSystemInfo             *prometheus.GaugeVec
+	peersEphemeral             *prometheus.GaugeVec
+	peerAvailabilityRatio      *prometheus.GaugeVec
+	peerApprovalPendingSince   *prometheus.GaugeVec
+	peersApprovalOldestAge     *prometheus.GaugeVec
+	peerApprovalWait           *prometheus.HistogramVec
+	peersDuplicate             *prometheus.GaugeVec
+	peersDuplicateValue        *prometheus.GaugeVec
+	peersByOSFamily            *prometheus.GaugeVec
+	peersByGroupCount          *prometheus.GaugeVec
+	peerGroupOverlap           *prometheus.GaugeVec
+
+	// Connection state observed for each peer during the previous poll
+	stateMu          sync.Mutex
+	connectionStates map[string]peerConnectionState
+	// Time each peer was first observed waiting for approval
+	approvalPendingSince map[string]time.Time
+
+	osNormalizer *osNormalizer
+
+	// Rolling connection history per peer, nil unless availability tracking is enabled
+	availability *availabilityTracker
+}
+
+// peerLocation is the geographic key used to aggregate peers by location
+type peerLocation struct {
+	countryCode string
+	cityName    string
+	geoNameID   string
+}
+
+// osRelease is a normalized operating system family and version
+type osRelease struct {
+	family  string
+	version string
+}
+
+// peerConnectionState is the connection state of a peer remembered between polls
+type peerConnectionState struct {
+	connected bool
+	lastSeen  time.Time
+	changedAt time.Time
 }
 
 // NewPeersExporter creates a new peers exporter
 func NewPeersExporter(client *nbclient.Client) *PeersExporter {
+	return NewPeersExporterWithOptions(client, DefaultOptions())
+}
+
+// NewPeersExporterWithOptions creates a new peers exporter using the given options
+func NewPeersExporterWithOptions(client *nbclient.Client, opts Options) *PeersExporter {
+	var availability *availabilityTracker
+	if opts.PeerAvailability {
+		availability = newAvailabilityTracker()
+		if opts.PeerAvailabilityStateFile != "" {
+			if err := availability.load(opts.PeerAvailabilityStateFile); err != nil {
+				logrus.WithError(err).WithField("path", opts.PeerAvailabilityStateFile).Warn("Failed to load peer availability state, starting empty")
+			}
+		}
+	}
+
 	return &PeersExporter{
 		client: client,
+		opts:   opts,
 
 		peersTotal: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
@@ -72,7 +136,7 @@ func NewPeersExporter(client *nbclient.Client) *PeersExporter {
 				Name: "netbird_peers_by_country",
 				Help: "Number of NetBird peers by country",
 			},
-			[]string{"country_code", "city_name"},
+			countryLabels(opts),
 		),
 
 		peersByGroup: prometheus.NewGaugeVec(
@@ -122,6 +186,124 @@ func NewPeersExporter(client *nbclient.Client) *PeersExporter {
 			},
 			[]string{"peer_name", "peer_id", "user_id", "connected"},
 		),
+
+		peerConnectionTransitions: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "netbird_peer_connection_transitions_total",
+				Help: "Total number of connection state transitions observed for each peer",
+			},
+			[]string{"peer_id", "direction"},
+		),
+
+		peerConnectionStateAge: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_peer_connection_state_age_seconds",
+				Help: "Seconds since the connection status of each peer last changed",
+			},
+			[]string{"peer_id", "connected"},
+		),
+
+		peersByKernelVersion: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_peers_by_kernel_version",
+				Help: "Number of NetBird peers by operating system kernel version",
+			},
+			[]string{"kernel_version"},
+		),
+
+		peerSystemInfo: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_peer_system_info",
+				Help: "Hardware and system information about each NetBird peer (always 1)",
+			},
+			[]string{"peer_id", "peer_name", "hostname", "serial_number", "os", "kernel_version", "version", "ui_version"},
+		),
+
+		peersEphemeral: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_peers_ephemeral",
+				Help: "Number of ephemeral NetBird peers",
+			},
+			[]string{"connected"},
+		),
+
+		peerAvailabilityRatio: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_peer_availability_ratio",
+				Help: "Share of observed time each peer was connected within a rolling window (0-1)",
+			},
+			[]string{"peer_id", "window"},
+		),
+
+		peerApprovalPendingSince: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_peer_approval_pending_since_timestamp",
+				Help: "Unix timestamp at which each peer was first observed waiting for approval",
+			},
+			[]string{"peer_id", "peer_name"},
+		),
+
+		peersApprovalOldestAge: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_peers_approval_pending_oldest_age_seconds",
+				Help: "Seconds the longest-waiting peer has been pending approval (0 when none are pending)",
+			},
+			[]string{},
+		),
+
+		peerApprovalWait: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "netbird_peer_approval_wait_seconds",
+				Help:    "Time peers spent pending approval, observed when they leave the pending state",
+				Buckets: prometheus.ExponentialBuckets(60, 4, 8),
+			},
+			[]string{"outcome"},
+		),
+
+		peersDuplicate: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_peers_duplicate",
+				Help: "Number of NetBird peers sharing a hostname, DNS label or serial number with another peer",
+			},
+			[]string{"kind"},
+		),
+
+		peersDuplicateValue: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_peers_duplicate_value",
+				Help: "Number of NetBird peers sharing each duplicated hostname, DNS label or serial number",
+			},
+			[]string{"kind", "value"},
+		),
+
+		peersByOSFamily: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_peers_by_os_family",
+				Help: "Number of NetBird peers by normalized operating system family and version",
+			},
+			[]string{"os_family", "os_version"},
+		),
+
+		peersByGroupCount: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_peers_by_group_count",
+				Help: "Number of NetBird peers by how many groups they belong to, including the All group",
+			},
+			[]string{"groups"},
+		),
+
+		peerGroupOverlap: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_peer_group_overlap",
+				Help: "Number of NetBird peers belonging to both groups of each configured pair",
+			},
+			[]string{"group_a", "group_b"},
+		),
+
+		osNormalizer:         newOSNormalizer(opts.OSFamilyMapping),
+		connectionStates:     make(map[string]peerConnectionState),
+		approvalPendingSince: make(map[string]time.Time),
+		availability:         availability,
 	}
 }
 
@@ -138,10 +320,29 @@ func (e *PeersExporter) Describe(ch chan<- *prometheus.Desc) {
 	e.peersApprovalRequired.Describe(ch)
 	e.accessiblePeersCount.Describe(ch)
 	e.peerConnectionStatusByName.Describe(ch)
+	e.peerConnectionTransitions.Describe(ch)
+	e.peerConnectionStateAge.Describe(ch)
+	e.peersByKernelVersion.Describe(ch)
+	e.peerSystemInfo.Describe(ch)
+	e.peersEphemeral.Describe(ch)
+	e.peerAvailabilityRatio.Describe(ch)
+	e.peerApprovalPendingSince.Describe(ch)
+	e.peersApprovalOldestAge.Describe(ch)
+	e.peerApprovalWait.Describe(ch)
+	e.peersDuplicate.Describe(ch)
+	e.peersDuplicateValue.Describe(ch)
+	e.peersByOSFamily.Describe(ch)
+	e.peersByGroupCount.Describe(ch)
+	e.peerGroupOverlap.Describe(ch)
 }
 
 // Collect implements prometheus.Collector
 func (e *PeersExporter) Collect(ch chan<- prometheus.Metric) {
+	e.collect(ch, newAPISnapshot(e.client))
+}
+
+// collect updates and collects the peer metrics from the objects of one scrape
+func (e *PeersExporter) collect(ch chan<- prometheus.Metric, snapshot *apiSnapshot) {
 	// Reset metrics before collecting new values
 	e.peersTotal.Reset()
 	e.peersConnected.Reset()
@@ -154,17 +355,34 @@ func (e *PeersExporter) Collect(ch chan<- prometheus.Metric) {
 	e.peersApprovalRequired.Reset()
 	e.accessiblePeersCount.Reset()
 	e.peerConnectionStatusByName.Reset()
+	e.peerConnectionStateAge.Reset()
+	e.peersByKernelVersion.Reset()
+	e.peerSystemInfo.Reset()
+	e.peersEphemeral.Reset()
+	e.peerAvailabilityRatio.Reset()
+	e.peerApprovalPendingSince.Reset()
+	e.peersApprovalOldestAge.Reset()
+	e.peersDuplicate.Reset()
+	e.peersDuplicateValue.Reset()
+	e.peersByOSFamily.Reset()
+	e.peersByGroupCount.Reset()
+	e.peerGroupOverlap.Reset()
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
 	defer cancel()
 
-	peers, err := e.client.Peers.List(ctx)
+	peers, err := snapshot.peers.get(ctx)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to fetch peers")
 		return
 	}
 
 	e.updateMetrics(peers)
+	e.updateDuplicateMetrics(peers)
+	e.updateGroupMembershipMetrics(peers)
+	e.updateConnectionTransitions(peers, time.Now())
+	e.updateAvailability(peers, time.Now())
+	e.updateApprovalQueue(peers, time.Now())
 
 	// Collect all metrics
 	e.peersTotal.Collect(ch)
@@ -178,6 +396,20 @@ func (e *PeersExporter) Collect(ch chan<- prometheus.Metric) {
 	e.peersApprovalRequired.Collect(ch)
 	e.accessiblePeersCount.Collect(ch)
 	e.peerConnectionStatusByName.Collect(ch)
+	e.peerConnectionTransitions.Collect(ch)
+	e.peerConnectionStateAge.Collect(ch)
+	e.peersByKernelVersion.Collect(ch)
+	e.peerSystemInfo.Collect(ch)
+	e.peersEphemeral.Collect(ch)
+	e.peerAvailabilityRatio.Collect(ch)
+	e.peerApprovalPendingSince.Collect(ch)
+	e.peersApprovalOldestAge.Collect(ch)
+	e.peerApprovalWait.Collect(ch)
+	e.peersDuplicate.Collect(ch)
+	e.peersDuplicateValue.Collect(ch)
+	e.peersByOSFamily.Collect(ch)
+	e.peersByGroupCount.Collect(ch)
+	e.peerGroupOverlap.Collect(ch)
 }
 
 // updateMetrics updates Prometheus metrics based on peer data
@@ -189,7 +421,9 @@ func (e *PeersExporter) updateMetrics(peers []api.Peer) {
 
 	// Count by categories
 	osCounts := make(map[string]int)
-	countryCounts := make(map[string]int)
+	kernelVersionCounts := make(map[string]int)
+	osFamilyCounts := make(map[osRelease]int)
+	countryCounts := make(map[peerLocation]int)
 	groupCounts := make(map[string]int)
 	sshEnabledCount := 0
 	sshDisabledCount := 0
@@ -197,6 +431,7 @@ func (e *PeersExporter) updateMetrics(peers []api.Peer) {
 	loginValidCount := 0
 	approvalRequiredCount := 0
 	approvalNotRequiredCount := 0
+	ephemeralCounts := make(map[bool]int)
 
 	for _, peer := range peers {
 		// Connection status
@@ -206,8 +441,16 @@ func (e *PeersExporter) updateMetrics(peers []api.Peer) {
 			disconnectedCount++
 		}
 
+		// Ephemeral status
+		if peer.Ephemeral {
+			ephemeralCounts[peer.Connected]++
+		}
+		perPeerSeries := e.includePeerSeries(peer)
+
 		// Last seen timestamp
-		e.peersLastSeen.WithLabelValues(peer.Id, peer.Name, peer.Hostname, peer.UserId).Set(float64(peer.LastSeen.Unix()))
+		if perPeerSeries {
+			e.peersLastSeen.WithLabelValues(peer.Id, peer.Name, peer.Hostname, peer.UserId).Set(float64(peer.LastSeen.Unix()))
+		}
 
 		// OS distribution
 		osKey := peer.Os
@@ -216,12 +459,25 @@ func (e *PeersExporter) updateMetrics(peers []api.Peer) {
 		}
 		osCounts[osKey]++
 
+		family, version := e.osNormalizer.normalize(peer.Os)
+		osFamilyCounts[osRelease{family: family, version: version}]++
+
+		// Kernel version distribution
+		kernelVersion := peer.KernelVersion
+		if kernelVersion == "" {
+			kernelVersion = "unknown"
+		}
+		kernelVersionCounts[kernelVersion]++
+
+		// System inventory
+		if e.opts.PeerSystemInfo && perPeerSeries {
+			e.peerSystemInfo.WithLabelValues(peer.Id, peer.Name, peer.Hostname, peer.SerialNumber, peer.Os, peer.KernelVersion, peer.Version, peer.UiVersion).Set(1)
+		}
+
 		// Country distribution
-		countryKey := fmt.Sprintf("%s_%s", peer.CountryCode, peer.CityName)
-		if peer.CountryCode == "" {
-			countryKey = "unknown_unknown"
+		if e.opts.PeerGeoGranularity != GeoGranularityNone {
+			countryCounts[e.peerLocation(peer)]++
 		}
-		countryCounts[countryKey]++
 
 		// Group membership
 		for _, group := range peer.Groups {
@@ -263,7 +519,9 @@ func (e *PeersExporter) updateMetrics(peers []api.Peer) {
 			userId = "unknown"
 		}
 
-		e.peerConnectionStatusByName.WithLabelValues(peer.Name, peer.Id, userId, connectedStr).Set(connectionValue)
+		if perPeerSeries {
+			e.peerConnectionStatusByName.WithLabelValues(peer.Name, peer.Id, userId, connectedStr).Set(connectionValue)
+		}
 	}
 
 	// Set metrics
@@ -276,15 +534,23 @@ func (e *PeersExporter) updateMetrics(peers []api.Peer) {
 		e.peersByOS.WithLabelValues(os).Set(float64(count))
 	}
 
+	// Normalized OS distribution
+	for release, count := range osFamilyCounts {
+		e.peersByOSFamily.WithLabelValues(release.family, release.version).Set(float64(count))
+	}
+
+	// Kernel version distribution
+	for kernelVersion, count := range kernelVersionCounts {
+		e.peersByKernelVersion.WithLabelValues(kernelVersion).Set(float64(count))
+	}
+
 	// Country distribution
-	for countryCity, count := range countryCounts {
-		parts := strings.SplitN(countryCity, "_", 2)
-		country := parts[0]
-		city := ""
-		if len(parts) > 1 {
-			city = parts[1]
+	for location, count := range countryCounts {
+		labels := []string{location.countryCode, location.cityName}
+		if e.opts.PeerGeoNameID {
+			labels = append(labels, location.geoNameID)
 		}
-		e.peersByCountry.WithLabelValues(country, city).Set(float64(count))
+		e.peersByCountry.WithLabelValues(labels...).Set(float64(count))
 	}
 
 	// Group distribution
@@ -310,6 +576,10 @@ func (e *PeersExporter) updateMetrics(peers []api.Peer) {
 	e.peersApprovalRequired.WithLabelValues("true").Set(float64(approvalRequiredCount))
 	e.peersApprovalRequired.WithLabelValues("false").Set(float64(approvalNotRequiredCount))
 
+	// Ephemeral status
+	e.peersEphemeral.WithLabelValues("true").Set(float64(ephemeralCounts[true]))
+	e.peersEphemeral.WithLabelValues("false").Set(float64(ephemeralCounts[false]))
+
 	logrus.WithFields(logrus.Fields{
 		"total_peers":             totalPeers,
 		"connected_peers":         connectedCount,
@@ -319,8 +589,376 @@ func (e *PeersExporter) updateMetrics(peers []api.Peer) {
 		"login_expired_peers":     loginExpiredCount,
 		"login_valid_peers":       loginValidCount,
 		"approval_required_peers": approvalRequiredCount,
+		"ephemeral_peers":         ephemeralCounts[true] + ephemeralCounts[false],
 		"os_distributions":        len(osCounts),
+		"kernel_versions":         len(kernelVersionCounts),
+		"os_families":             len(osFamilyCounts),
 		"country_distributions":   len(countryCounts),
 		"group_memberships":       len(groupCounts),
 	}).Debug("Updated peer metrics")
 }
+
+// countryLabels returns the labels of netbird_peers_by_country for the given options
+func countryLabels(opts Options) []string {
+	labels := []string{"country_code", "city_name"}
+	if opts.PeerGeoNameID {
+		labels = append(labels, "geoname_id")
+	}
+	return labels
+}
+
+// peerLocation returns the location key of a peer at the configured granularity
+func (e *PeersExporter) peerLocation(peer api.Peer) peerLocation {
+	// At country granularity city_name is always empty, also for unknown peers
+	byCity := e.opts.PeerGeoGranularity != GeoGranularityCountry
+	if peer.CountryCode == "" {
+		location := peerLocation{countryCode: "unknown"}
+		if byCity {
+			location.cityName = "unknown"
+		}
+		return location
+	}
+
+	location := peerLocation{countryCode: string(peer.CountryCode)}
+	if byCity {
+		location.cityName = string(peer.CityName)
+		if peer.GeonameId != 0 {
+			location.geoNameID = strconv.Itoa(peer.GeonameId)
+		}
+	}
+	return location
+}
+
+// placeholderSerialNumbers are vendor defaults that do not identify a device
+var placeholderSerialNumbers = map[string]bool{
+	"0":                      true,
+	"none":                   true,
+	"default string":         true,
+	"not specified":          true,
+	"system serial number":   true,
+	"to be filled by o.e.m.": true,
+}
+
+// updateDuplicateMetrics detects peers sharing a hostname, DNS label or serial
+// number, which usually indicates a re-enrolled device with a stale peer left behind
+func (e *PeersExporter) updateDuplicateMetrics(peers []api.Peer) {
+	values := map[string]map[string]int{
+		"hostname":  make(map[string]int),
+		"dns_label": make(map[string]int),
+		"serial":    make(map[string]int),
+	}
+
+	dnsLabels := make(map[string]bool, len(peers))
+	for _, peer := range peers {
+		if dnsLabel := strings.ToLower(strings.TrimSpace(peer.DnsLabel)); dnsLabel != "" {
+			dnsLabels[dnsLabel] = true
+		}
+	}
+
+	for _, peer := range peers {
+		if hostname := strings.ToLower(strings.TrimSpace(peer.Hostname)); hostname != "" {
+			values["hostname"][hostname]++
+		}
+		if dnsLabel := strings.ToLower(strings.TrimSpace(peer.DnsLabel)); dnsLabel != "" {
+			values["dns_label"][baseDNSLabel(dnsLabel, dnsLabels)]++
+		}
+		if serial := strings.TrimSpace(peer.SerialNumber); serial != "" && !placeholderSerialNumbers[strings.ToLower(serial)] {
+			values["serial"][serial]++
+		}
+	}
+
+	duplicates := make(logrus.Fields, len(values))
+	for kind, counts := range values {
+		duplicatedPeers := 0
+		for value, count := range counts {
+			if count < 2 {
+				continue
+			}
+			duplicatedPeers += count
+			e.peersDuplicateValue.WithLabelValues(kind, value).Set(float64(count))
+		}
+		e.peersDuplicate.WithLabelValues(kind).Set(float64(duplicatedPeers))
+		duplicates[kind] = duplicatedPeers
+	}
+
+	logrus.WithFields(duplicates).Debug("Updated peer duplicate metrics")
+}
+
+// baseDNSLabel strips the numeric suffix NetBird appends to make a DNS label
+// unique, such as "laptop-2" for a second "laptop". The suffix is only removed
+// when the label without it is also in use, so a peer named "web-1" is kept as is.
+func baseDNSLabel(label string, labels map[string]bool) string {
+	base, suffix, ok := cutLast(label, "-")
+	if !ok || len(suffix) == 0 || len(suffix) > 3 || !labels[base] {
+		return label
+	}
+	for _, r := range suffix {
+		if r < '0' || r > '9' {
+			return label
+		}
+	}
+	return base
+}
+
+// cutLast slices s around the last instance of sep
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// groupCountBuckets are the ranges used to bucket peers by their number of groups
+var groupCountBuckets = []struct {
+	label string
+	max   int
+}{
+	{"0", 0},
+	{"1", 1},
+	{"2-5", 5},
+	{"6+", -1},
+}
+
+// groupCountBucket returns the bucket label for a number of groups
+func groupCountBucket(count int) string {
+	for _, bucket := range groupCountBuckets {
+		if bucket.max < 0 || count <= bucket.max {
+			return bucket.label
+		}
+	}
+	return groupCountBuckets[len(groupCountBuckets)-1].label
+}
+
+// updateGroupMembershipMetrics buckets peers by their number of groups and, when
+// configured, counts peers shared by each pair of selected groups. Since every peer
+// is in the All group, the "1" bucket holds peers in no other group.
+func (e *PeersExporter) updateGroupMembershipMetrics(peers []api.Peer) {
+	bucketCounts := make(map[string]int, len(groupCountBuckets))
+	for _, bucket := range groupCountBuckets {
+		bucketCounts[bucket.label] = 0
+	}
+
+	selected := e.opts.PeerGroupOverlap
+	overlapCounts := make(map[[2]int]int)
+
+	for _, peer := range peers {
+		groupIDs := make(map[string]bool, len(peer.Groups))
+		groupNames := make(map[string]bool, len(peer.Groups))
+		for _, group := range peer.Groups {
+			groupIDs[group.Id] = true
+			groupNames[group.Name] = true
+		}
+		bucketCounts[groupCountBucket(len(groupIDs))]++
+
+		if len(selected) < 2 {
+			continue
+		}
+		member := make([]bool, len(selected))
+		for i, group := range selected {
+			member[i] = groupIDs[group] || groupNames[group]
+		}
+		for i := range selected {
+			for j := i + 1; j < len(selected); j++ {
+				if member[i] && member[j] {
+					overlapCounts[[2]int{i, j}]++
+				}
+			}
+		}
+	}
+
+	for bucket, count := range bucketCounts {
+		e.peersByGroupCount.WithLabelValues(bucket).Set(float64(count))
+	}
+
+	// Report every configured pair, including those without shared peers
+	for i := range selected {
+		for j := i + 1; j < len(selected); j++ {
+			e.peerGroupOverlap.WithLabelValues(selected[i], selected[j]).Set(float64(overlapCounts[[2]int{i, j}]))
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"group_count_buckets": bucketCounts,
+		"overlap_groups":      len(selected),
+	}).Debug("Updated peer group membership metrics")
+}
+
+// includePeerSeries reports whether per-peer series should be exported for the peer
+func (e *PeersExporter) includePeerSeries(peer api.Peer) bool {
+	return !peer.Ephemeral || !e.opts.ExcludeEphemeralPeers
+}
+
+// updateConnectionTransitions compares the connection status of each peer with
+// the previous poll and counts transitions. NetBird refreshes LastSeen whenever
+// a peer connects or disconnects, so an unchanged status with an advanced
+// LastSeen means the peer flapped between two polls.
+func (e *PeersExporter) updateConnectionTransitions(peers []api.Peer, now time.Time) {
+	e.stateMu.Lock()
+	defer e.stateMu.Unlock()
+
+	seen := make(map[string]bool, len(peers))
+	flaps := 0
+
+	for _, peer := range peers {
+		if !e.includePeerSeries(peer) {
+			continue
+		}
+		seen[peer.Id] = true
+
+		previous, known := e.connectionStates[peer.Id]
+		current := peerConnectionState{
+			connected: peer.Connected,
+			lastSeen:  peer.LastSeen,
+			changedAt: previous.changedAt,
+		}
+
+		switch {
+		case !known:
+			// First observation: the last status change is best approximated by LastSeen
+			current.changedAt = now
+			if !peer.LastSeen.IsZero() && peer.LastSeen.Before(now) {
+				current.changedAt = peer.LastSeen
+			}
+		case previous.connected != peer.Connected:
+			e.peerConnectionTransitions.WithLabelValues(peer.Id, transitionDirection(peer.Connected)).Inc()
+			current.changedAt = stateChangeTime(previous, peer.LastSeen, now)
+		case peer.LastSeen.After(previous.lastSeen) && !previous.lastSeen.IsZero():
+			// Same status as before but LastSeen moved: the peer left and came back.
+			// NetBird only writes LastSeen when a peer connects or disconnects, but a
+			// new sync stream of an already connected peer (e.g. after a management
+			// server restart) counts as a connect too, so it shows up here as a flap.
+			e.peerConnectionTransitions.WithLabelValues(peer.Id, transitionDirection(!peer.Connected)).Inc()
+			e.peerConnectionTransitions.WithLabelValues(peer.Id, transitionDirection(peer.Connected)).Inc()
+			current.changedAt = stateChangeTime(previous, peer.LastSeen, now)
+			flaps++
+		}
+
+		e.connectionStates[peer.Id] = current
+		e.peerConnectionStateAge.WithLabelValues(peer.Id, strconv.FormatBool(peer.Connected)).Set(now.Sub(current.changedAt).Seconds())
+	}
+
+	// Forget peers that were removed from the account
+	for peerID := range e.connectionStates {
+		if !seen[peerID] {
+			delete(e.connectionStates, peerID)
+			e.peerConnectionTransitions.DeletePartialMatch(prometheus.Labels{"peer_id": peerID})
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"tracked_peers": len(e.connectionStates),
+		"flapped_peers": flaps,
+	}).Debug("Updated peer connection transitions")
+}
+
+// transitionDirection returns the direction label for a transition into the given status
+func transitionDirection(connected bool) string {
+	if connected {
+		return "connected"
+	}
+	return "disconnected"
+}
+
+// stateChangeTime returns when a peer changed status, preferring the LastSeen
+// reported by NetBird and falling back to the poll time
+func stateChangeTime(previous peerConnectionState, lastSeen, now time.Time) time.Time {
+	if lastSeen.After(previous.changedAt) && !lastSeen.After(now) {
+		return lastSeen
+	}
+	return now
+}
+
+// updateAvailability records a connection sample for each peer and exports the
+// availability ratio over each rolling window
+func (e *PeersExporter) updateAvailability(peers []api.Peer, now time.Time) {
+	if e.availability == nil {
+		return
+	}
+
+	if interval, ok := e.availability.observeScrape(now); !ok {
+		logrus.WithField("interval", interval).Warn("Time since the previous scrape is far above the usual scrape interval, not counting it towards peer availability")
+	}
+
+	seen := make(map[string]bool, len(peers))
+	for _, peer := range peers {
+		if !e.includePeerSeries(peer) {
+			continue
+		}
+		seen[peer.Id] = true
+
+		e.availability.record(peer.Id, peer.Connected, now)
+		for _, window := range availabilityWindows {
+			if ratio, ok := e.availability.ratio(peer.Id, window.duration, now); ok {
+				e.peerAvailabilityRatio.WithLabelValues(peer.Id, window.label).Set(ratio)
+			}
+		}
+	}
+	e.availability.retain(seen)
+
+	// Persist in the background so that disk writes never delay a scrape
+	if path := e.opts.PeerAvailabilityStateFile; path != "" {
+		go func() {
+			if err := e.availability.save(path, now); err != nil {
+				logrus.WithError(err).WithField("path", path).Error("Failed to persist peer availability state")
+			}
+		}()
+	}
+}
+
+// SaveState writes the peer availability history to its state file, if one is
+// configured, regardless of the persist interval
+func (e *PeersExporter) SaveState() error {
+	if e.availability == nil || e.opts.PeerAvailabilityStateFile == "" {
+		return nil
+	}
+	return e.availability.flush(e.opts.PeerAvailabilityStateFile)
+}
+
+// updateApprovalQueue tracks how long peers have been waiting for approval and
+// observes the wait time once a peer is approved or removed
+func (e *PeersExporter) updateApprovalQueue(peers []api.Peer, now time.Time) {
+	e.stateMu.Lock()
+	defer e.stateMu.Unlock()
+
+	present := make(map[string]bool, len(peers))
+	oldest := time.Duration(0)
+
+	for _, peer := range peers {
+		present[peer.Id] = true
+
+		since, pending := e.approvalPendingSince[peer.Id]
+		if !peer.ApprovalRequired {
+			if pending {
+				e.peerApprovalWait.WithLabelValues("approved").Observe(now.Sub(since).Seconds())
+				delete(e.approvalPendingSince, peer.Id)
+			}
+			continue
+		}
+
+		if !pending {
+			since = now
+			e.approvalPendingSince[peer.Id] = since
+		}
+		if age := now.Sub(since); age > oldest {
+			oldest = age
+		}
+		if e.includePeerSeries(peer) {
+			e.peerApprovalPendingSince.WithLabelValues(peer.Id, peer.Name).Set(float64(since.Unix()))
+		}
+	}
+
+	// Peers deleted while pending leave the queue without being approved
+	for peerID, since := range e.approvalPendingSince {
+		if !present[peerID] {
+			e.peerApprovalWait.WithLabelValues("removed").Observe(now.Sub(since).Seconds())
+			delete(e.approvalPendingSince, peerID)
+		}
+	}
+
+	e.peersApprovalOldestAge.WithLabelValues().Set(oldest.Seconds())
+
+	logrus.WithFields(logrus.Fields{
+		"pending_peers":      len(e.approvalPendingSince),
+		"oldest_pending_age": oldest,
+	}).Debug("Updated peer approval queue")
+}
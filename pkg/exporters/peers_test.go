@@ -532,3 +532,427 @@ func TestPeersExporter_ConnectionStatusByName(t *testing.T) {
 		t.Error("Expected to find disconnected peer metric")
 	}
 }
+
+func TestPeersExporter_ConnectionTransitions(t *testing.T) {
+	client := nbclient.New("https://api.netbird.io", "test-token")
+	exporter := NewPeersExporter(client)
+
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	peers := []api.Peer{
+		{Id: "peer1", Connected: true, LastSeen: start.Add(-time.Hour)},
+		{Id: "peer2", Connected: true, LastSeen: start.Add(-time.Hour)},
+		{Id: "peer3", Connected: false, LastSeen: start.Add(-time.Hour)},
+	}
+	exporter.updateConnectionTransitions(peers, start)
+
+	// First poll only records state
+	if _, found := gatherMetricValue(t, exporter.peerConnectionTransitions, "netbird_peer_connection_transitions_total", map[string]string{"peer_id": "peer1"}); found {
+		t.Error("Expected no transitions after the first poll")
+	}
+	age, _ := gatherMetricValue(t, exporter.peerConnectionStateAge, "netbird_peer_connection_state_age_seconds", map[string]string{"peer_id": "peer1"})
+	if age != time.Hour.Seconds() {
+		t.Errorf("Expected state age of %f seconds, got %f", time.Hour.Seconds(), age)
+	}
+
+	// peer1 disconnects, peer2 flaps between polls, peer3 is removed
+	next := start.Add(time.Minute)
+	peers = []api.Peer{
+		{Id: "peer1", Connected: false, LastSeen: start.Add(30 * time.Second)},
+		{Id: "peer2", Connected: true, LastSeen: start.Add(20 * time.Second)},
+	}
+	exporter.updateConnectionTransitions(peers, next)
+
+	tests := []struct {
+		peerID    string
+		direction string
+		expected  float64
+	}{
+		{"peer1", "disconnected", 1},
+		{"peer2", "disconnected", 1},
+		{"peer2", "connected", 1},
+	}
+	for _, tt := range tests {
+		value, found := gatherMetricValue(t, exporter.peerConnectionTransitions, "netbird_peer_connection_transitions_total", map[string]string{"peer_id": tt.peerID, "direction": tt.direction})
+		if !found || value != tt.expected {
+			t.Errorf("Expected %s %s transitions to be %f, got %f (found=%v)", tt.peerID, tt.direction, tt.expected, value, found)
+		}
+	}
+
+	if _, found := gatherMetricValue(t, exporter.peerConnectionTransitions, "netbird_peer_connection_transitions_total", map[string]string{"peer_id": "peer1", "direction": "connected"}); found {
+		t.Error("Expected no connected transition for peer1")
+	}
+
+	age, _ = gatherMetricValue(t, exporter.peerConnectionStateAge, "netbird_peer_connection_state_age_seconds", map[string]string{"peer_id": "peer1", "connected": "false"})
+	if age != 30 {
+		t.Errorf("Expected peer1 state age of 30 seconds, got %f", age)
+	}
+
+	if _, tracked := exporter.connectionStates["peer3"]; tracked {
+		t.Error("Expected removed peer to be forgotten")
+	}
+}
+
+func TestPeersExporter_SystemInventory(t *testing.T) {
+	client := nbclient.New("https://api.netbird.io", "test-token")
+
+	peers := []api.Peer{
+		{Id: "peer1", Name: "laptop", Hostname: "laptop-1", SerialNumber: "SN123", Os: "linux", KernelVersion: "6.8.0"},
+		{Id: "peer2", Name: "server", Hostname: "server-1", SerialNumber: "SN456", Os: "linux", KernelVersion: "6.8.0"},
+		{Id: "peer3", Name: "phone", Hostname: "phone-1", Os: "android"},
+	}
+
+	exporter := NewPeersExporter(client)
+	exporter.updateMetrics(peers)
+
+	count, _ := gatherMetricValue(t, exporter.peersByKernelVersion, "netbird_peers_by_kernel_version", map[string]string{"kernel_version": "6.8.0"})
+	if count != 2 {
+		t.Errorf("Expected 2 peers with kernel 6.8.0, got %f", count)
+	}
+	unknown, _ := gatherMetricValue(t, exporter.peersByKernelVersion, "netbird_peers_by_kernel_version", map[string]string{"kernel_version": "unknown"})
+	if unknown != 1 {
+		t.Errorf("Expected 1 peer with unknown kernel, got %f", unknown)
+	}
+	if _, found := gatherMetricValue(t, exporter.peerSystemInfo, "netbird_peer_system_info", map[string]string{"peer_id": "peer1"}); found {
+		t.Error("Expected system info to be disabled by default")
+	}
+
+	opts := DefaultOptions()
+	opts.PeerSystemInfo = true
+	exporter = NewPeersExporterWithOptions(client, opts)
+	exporter.updateMetrics(peers)
+
+	if _, found := gatherMetricValue(t, exporter.peerSystemInfo, "netbird_peer_system_info", map[string]string{"peer_id": "peer1", "serial_number": "SN123", "kernel_version": "6.8.0"}); !found {
+		t.Error("Expected system info series for peer1 when enabled")
+	}
+}
+
+func TestPeersExporter_EphemeralPeers(t *testing.T) {
+	client := nbclient.New("https://api.netbird.io", "test-token")
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	peers := []api.Peer{
+		{Id: "peer1", Name: "laptop", Connected: true},
+		{Id: "ci1", Name: "ci-runner-1", Connected: true, Ephemeral: true},
+		{Id: "ci2", Name: "ci-runner-2", Connected: false, Ephemeral: true},
+	}
+
+	exporter := NewPeersExporter(client)
+	exporter.updateMetrics(peers)
+
+	connected, _ := gatherMetricValue(t, exporter.peersEphemeral, "netbird_peers_ephemeral", map[string]string{"connected": "true"})
+	disconnected, _ := gatherMetricValue(t, exporter.peersEphemeral, "netbird_peers_ephemeral", map[string]string{"connected": "false"})
+	if connected != 1 || disconnected != 1 {
+		t.Errorf("Expected 1 connected and 1 disconnected ephemeral peer, got %f and %f", connected, disconnected)
+	}
+	if _, found := gatherMetricValue(t, exporter.peerConnectionStatusByName, "netbird_peer_connection_status_by_name", map[string]string{"peer_id": "ci1"}); !found {
+		t.Error("Expected ephemeral peers in per-peer series by default")
+	}
+
+	opts := DefaultOptions()
+	opts.ExcludeEphemeralPeers = true
+	exporter = NewPeersExporterWithOptions(client, opts)
+	exporter.updateMetrics(peers)
+	exporter.updateConnectionTransitions(peers, now)
+
+	total, _ := gatherMetricValue(t, exporter.peersTotal, "netbird_peers", map[string]string{})
+	if total != 3 {
+		t.Errorf("Expected aggregate total to still include ephemeral peers, got %f", total)
+	}
+	for _, peerID := range []string{"ci1", "ci2"} {
+		if _, found := gatherMetricValue(t, exporter.peerConnectionStatusByName, "netbird_peer_connection_status_by_name", map[string]string{"peer_id": peerID}); found {
+			t.Errorf("Expected %s to be excluded from per-peer series", peerID)
+		}
+		if _, found := gatherMetricValue(t, exporter.peersLastSeen, "netbird_peer_last_seen_timestamp", map[string]string{"peer_id": peerID}); found {
+			t.Errorf("Expected %s to be excluded from last seen series", peerID)
+		}
+		if _, tracked := exporter.connectionStates[peerID]; tracked {
+			t.Errorf("Expected %s not to be tracked for transitions", peerID)
+		}
+	}
+	if _, found := gatherMetricValue(t, exporter.peerConnectionStatusByName, "netbird_peer_connection_status_by_name", map[string]string{"peer_id": "peer1"}); !found {
+		t.Error("Expected permanent peer to remain in per-peer series")
+	}
+}
+
+func TestPeersExporter_GeoGranularity(t *testing.T) {
+	client := nbclient.New("https://api.netbird.io", "test-token")
+
+	peers := []api.Peer{
+		{Id: "peer1", CountryCode: "US", CityName: "New_York", GeonameId: 5128581},
+		{Id: "peer2", CountryCode: "US", CityName: "Los Angeles", GeonameId: 5368361},
+		{Id: "peer3", CountryCode: "DE", CityName: "Berlin", GeonameId: 2950159},
+		{Id: "peer4"},
+	}
+
+	// City granularity keeps city names intact, even with underscores
+	exporter := NewPeersExporter(client)
+	exporter.updateMetrics(peers)
+
+	count, _ := gatherMetricValue(t, exporter.peersByCountry, "netbird_peers_by_country", map[string]string{"country_code": "US", "city_name": "New_York"})
+	if count != 1 {
+		t.Errorf("Expected 1 peer in US/New_York, got %f", count)
+	}
+	unknown, _ := gatherMetricValue(t, exporter.peersByCountry, "netbird_peers_by_country", map[string]string{"country_code": "unknown", "city_name": "unknown"})
+	if unknown != 1 {
+		t.Errorf("Expected 1 peer with unknown location, got %f", unknown)
+	}
+
+	// Country granularity drops city names
+	opts := DefaultOptions()
+	opts.PeerGeoGranularity = GeoGranularityCountry
+	exporter = NewPeersExporterWithOptions(client, opts)
+	exporter.updateMetrics(peers)
+
+	count, _ = gatherMetricValue(t, exporter.peersByCountry, "netbird_peers_by_country", map[string]string{"country_code": "US", "city_name": ""})
+	if count != 2 {
+		t.Errorf("Expected 2 peers in US at country granularity, got %f", count)
+	}
+	unknown, _ = gatherMetricValue(t, exporter.peersByCountry, "netbird_peers_by_country", map[string]string{"country_code": "unknown", "city_name": ""})
+	if unknown != 1 {
+		t.Errorf("Expected 1 peer with unknown country and empty city at country granularity, got %f", unknown)
+	}
+
+	// GeoNames IDs are added as a label when enabled
+	opts = DefaultOptions()
+	opts.PeerGeoNameID = true
+	exporter = NewPeersExporterWithOptions(client, opts)
+	exporter.updateMetrics(peers)
+
+	if _, found := gatherMetricValue(t, exporter.peersByCountry, "netbird_peers_by_country", map[string]string{"country_code": "DE", "city_name": "Berlin", "geoname_id": "2950159"}); !found {
+		t.Error("Expected geoname_id label on peers by country")
+	}
+
+	// No granularity disables the distribution
+	opts = DefaultOptions()
+	opts.PeerGeoGranularity = GeoGranularityNone
+	exporter = NewPeersExporterWithOptions(client, opts)
+	exporter.updateMetrics(peers)
+
+	if _, found := gatherMetricValue(t, exporter.peersByCountry, "netbird_peers_by_country", map[string]string{}); found {
+		t.Error("Expected no peers by country series when geo granularity is none")
+	}
+}
+
+func TestPeersExporter_Availability(t *testing.T) {
+	client := nbclient.New("https://api.netbird.io", "test-token")
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	peers := []api.Peer{{Id: "peer1", Connected: true}}
+
+	// Disabled by default
+	exporter := NewPeersExporter(client)
+	exporter.updateAvailability(peers, start)
+	if exporter.availability != nil {
+		t.Error("Expected availability tracking to be disabled by default")
+	}
+
+	opts := DefaultOptions()
+	opts.PeerAvailability = true
+	exporter = NewPeersExporterWithOptions(client, opts)
+	exporter.updateAvailability(peers, start)
+	exporter.updateAvailability(peers, start.Add(time.Minute))
+
+	for _, window := range []string{"1h", "24h", "7d"} {
+		ratio, found := gatherMetricValue(t, exporter.peerAvailabilityRatio, "netbird_peer_availability_ratio", map[string]string{"peer_id": "peer1", "window": window})
+		if !found || ratio != 1 {
+			t.Errorf("Expected %s availability of 1, got %f (found=%v)", window, ratio, found)
+		}
+	}
+}
+
+func TestPeersExporter_ApprovalQueue(t *testing.T) {
+	client := nbclient.New("https://api.netbird.io", "test-token")
+	exporter := NewPeersExporter(client)
+
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	exporter.updateApprovalQueue([]api.Peer{
+		{Id: "peer1", ApprovalRequired: true},
+		{Id: "peer2", ApprovalRequired: false},
+	}, start)
+
+	later := start.Add(10 * time.Minute)
+	exporter.updateApprovalQueue([]api.Peer{
+		{Id: "peer1", ApprovalRequired: true},
+		{Id: "peer2", ApprovalRequired: false},
+		{Id: "peer3", ApprovalRequired: true},
+	}, later)
+
+	oldest, _ := gatherMetricValue(t, exporter.peersApprovalOldestAge, "netbird_peers_approval_pending_oldest_age_seconds", map[string]string{})
+	if oldest != 600 {
+		t.Errorf("Expected oldest pending age of 600 seconds, got %f", oldest)
+	}
+	since, _ := gatherMetricValue(t, exporter.peerApprovalPendingSince, "netbird_peer_approval_pending_since_timestamp", map[string]string{"peer_id": "peer1"})
+	if since != float64(start.Unix()) {
+		t.Errorf("Expected peer1 pending since %d, got %f", start.Unix(), since)
+	}
+
+	// peer1 is approved, peer3 is deleted while pending
+	exporter.updateApprovalQueue([]api.Peer{
+		{Id: "peer1", ApprovalRequired: false},
+		{Id: "peer2", ApprovalRequired: false},
+	}, start.Add(time.Hour))
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(exporter.peerApprovalWait)
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Failed to gather metrics: %v", err)
+	}
+
+	observed := map[string]float64{}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "outcome" {
+					observed[label.GetValue()] = metric.GetHistogram().GetSampleSum()
+				}
+			}
+		}
+	}
+	if observed["approved"] != time.Hour.Seconds() {
+		t.Errorf("Expected approved wait of 3600 seconds, got %f", observed["approved"])
+	}
+	if observed["removed"] != (50 * time.Minute).Seconds() {
+		t.Errorf("Expected removed wait of 3000 seconds, got %f", observed["removed"])
+	}
+	if len(exporter.approvalPendingSince) != 0 {
+		t.Errorf("Expected empty approval queue, got %d entries", len(exporter.approvalPendingSince))
+	}
+}
+
+func TestPeersExporter_DuplicateDetection(t *testing.T) {
+	client := nbclient.New("https://api.netbird.io", "test-token")
+	exporter := NewPeersExporter(client)
+
+	peers := []api.Peer{
+		{Id: "peer1", Hostname: "Laptop-1", DnsLabel: "laptop-1", SerialNumber: "SN123"},
+		{Id: "peer2", Hostname: "laptop-1", DnsLabel: "laptop-1-abc", SerialNumber: "SN123"},
+		{Id: "peer3", Hostname: "server", DnsLabel: "server", SerialNumber: "To be filled by O.E.M."},
+		{Id: "peer4", Hostname: "vm", DnsLabel: "vm", SerialNumber: "To be filled by O.E.M."},
+	}
+
+	exporter.updateDuplicateMetrics(peers)
+
+	tests := []struct {
+		kind     string
+		expected float64
+	}{
+		{"hostname", 2},
+		{"dns_label", 0},
+		{"serial", 2},
+	}
+	for _, tt := range tests {
+		value, found := gatherMetricValue(t, exporter.peersDuplicate, "netbird_peers_duplicate", map[string]string{"kind": tt.kind})
+		if !found || value != tt.expected {
+			t.Errorf("Expected %f duplicate peers by %s, got %f (found=%v)", tt.expected, tt.kind, value, found)
+		}
+	}
+
+	count, _ := gatherMetricValue(t, exporter.peersDuplicateValue, "netbird_peers_duplicate_value", map[string]string{"kind": "hostname", "value": "laptop-1"})
+	if count != 2 {
+		t.Errorf("Expected 2 peers sharing hostname laptop-1, got %f", count)
+	}
+	if _, found := gatherMetricValue(t, exporter.peersDuplicateValue, "netbird_peers_duplicate_value", map[string]string{"kind": "serial", "value": "To be filled by O.E.M."}); found {
+		t.Error("Expected placeholder serial numbers to be ignored")
+	}
+}
+
+func TestPeersExporter_OSFamily(t *testing.T) {
+	client := nbclient.New("https://api.netbird.io", "test-token")
+	exporter := NewPeersExporter(client)
+
+	exporter.updateMetrics([]api.Peer{
+		{Id: "peer1", Os: "Ubuntu 22.04.3 LTS"},
+		{Id: "peer2", Os: "Ubuntu 22.04.4 LTS"},
+		{Id: "peer3", Os: "Windows 10.0.22631"},
+	})
+
+	ubuntu, _ := gatherMetricValue(t, exporter.peersByOSFamily, "netbird_peers_by_os_family", map[string]string{"os_family": "linux", "os_version": "22.04"})
+	if ubuntu != 2 {
+		t.Errorf("Expected 2 linux 22.04 peers, got %f", ubuntu)
+	}
+	windows, _ := gatherMetricValue(t, exporter.peersByOSFamily, "netbird_peers_by_os_family", map[string]string{"os_family": "windows", "os_version": "11"})
+	if windows != 1 {
+		t.Errorf("Expected 1 windows 11 peer, got %f", windows)
+	}
+
+	// The raw OS metric is kept alongside the normalized one
+	raw, _ := gatherMetricValue(t, exporter.peersByOS, "netbird_peers_by_os", map[string]string{"os": "Ubuntu 22.04.3 LTS"})
+	if raw != 1 {
+		t.Errorf("Expected raw OS series to be preserved, got %f", raw)
+	}
+}
+
+func TestPeersExporter_GroupMembership(t *testing.T) {
+	client := nbclient.New("https://api.netbird.io", "test-token")
+	opts := DefaultOptions()
+	opts.PeerGroupOverlap = []string{"Devs", "servers", "Ops"}
+	exporter := NewPeersExporterWithOptions(client, opts)
+
+	all := api.GroupMinimum{Id: "all", Name: "All"}
+	devs := api.GroupMinimum{Id: "devs", Name: "Devs"}
+	servers := api.GroupMinimum{Id: "servers", Name: "Servers"}
+	exporter.updateGroupMembershipMetrics([]api.Peer{
+		{Id: "peer1", Groups: []api.GroupMinimum{all}},
+		{Id: "peer2", Groups: []api.GroupMinimum{all, devs}},
+		{Id: "peer3", Groups: []api.GroupMinimum{all, devs, servers}},
+		{Id: "peer4", Groups: []api.GroupMinimum{
+			all, devs, servers, {Id: "g4", Name: "G4"}, {Id: "g5", Name: "G5"}, {Id: "g6", Name: "G6"},
+		}},
+	})
+
+	bucketTests := []struct {
+		bucket   string
+		expected float64
+	}{
+		{"0", 0},
+		{"1", 1},
+		{"2-5", 2},
+		{"6+", 1},
+	}
+	for _, tt := range bucketTests {
+		value, found := gatherMetricValue(t, exporter.peersByGroupCount, "netbird_peers_by_group_count", map[string]string{"groups": tt.bucket})
+		if !found || value != tt.expected {
+			t.Errorf("Expected %f peers in bucket %s, got %f (found=%v)", tt.expected, tt.bucket, value, found)
+		}
+	}
+
+	overlapTests := []struct {
+		groupA   string
+		groupB   string
+		expected float64
+	}{
+		{"Devs", "servers", 2},
+		{"Devs", "Ops", 0},
+		{"servers", "Ops", 0},
+	}
+	for _, tt := range overlapTests {
+		value, found := gatherMetricValue(t, exporter.peerGroupOverlap, "netbird_peer_group_overlap", map[string]string{"group_a": tt.groupA, "group_b": tt.groupB})
+		if !found || value != tt.expected {
+			t.Errorf("Expected overlap of %s and %s to be %f, got %f (found=%v)", tt.groupA, tt.groupB, tt.expected, value, found)
+		}
+	}
+}
+
+func TestPeersExporter_DuplicateDNSLabelReenrollment(t *testing.T) {
+	client := nbclient.New("https://api.netbird.io", "test-token")
+	exporter := NewPeersExporter(client)
+
+	// NetBird keeps DNS labels unique by appending a numeric suffix, so a
+	// re-enrolled device gets "macbook-1" next to the stale "macbook" peer
+	exporter.updateDuplicateMetrics([]api.Peer{
+		{Id: "peer1", Hostname: "MacBook", DnsLabel: "macbook"},
+		{Id: "peer2", Hostname: "macbook.local", DnsLabel: "macbook-1"},
+		{Id: "peer3", Hostname: "web-1", DnsLabel: "web-1"},
+		{Id: "peer4", Hostname: "web-2", DnsLabel: "web-2"},
+		{Id: "peer5", Hostname: "db", DnsLabel: "db-backup"},
+	})
+
+	duplicates, _ := gatherMetricValue(t, exporter.peersDuplicate, "netbird_peers_duplicate", map[string]string{"kind": "dns_label"})
+	if duplicates != 2 {
+		t.Errorf("Expected 2 peers sharing a DNS label, got %f", duplicates)
+	}
+	count, _ := gatherMetricValue(t, exporter.peersDuplicateValue, "netbird_peers_duplicate_value", map[string]string{"kind": "dns_label", "value": "macbook"})
+	if count != 2 {
+		t.Errorf("Expected the re-enrolled pair to share DNS label macbook, got %f", count)
+	}
+}
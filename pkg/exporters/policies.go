@@ -2,7 +2,10 @@ package exporters
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"strconv"
+	"sync"
 	"time"
 
 	nbclient "github.com/netbirdio/netbird/shared/management/client/rest"
@@ -14,6 +17,7 @@ import (
 // PoliciesExporter handles policy-specific metrics collection
 type PoliciesExporter struct {
 	client *nbclient.Client
+	opts   Options
 
 	// Prometheus metrics for policies
 	policiesTotal       *prometheus.GaugeVec
@@ -22,14 +26,25 @@ type PoliciesExporter struct {
 	policyRulesByProto  *prometheus.GaugeVec
 	policyRulesByAction *prometheus.GaugeVec
 	policyInfo          *prometheus.GaugeVec
+	policyRuleEdge      *prometheus.GaugeVec
+	policyRuleFindings  *prometheus.GaugeVec
+	policyRulePorts     *prometheus.GaugeVec
+	policyRulesByPort   *prometheus.GaugeVec
+	policyRuleWideRange *prometheus.GaugeVec
 	scrapeErrorsTotal   *prometheus.CounterVec
 	scrapeDuration      *prometheus.HistogramVec
 }
 
 // NewPoliciesExporter creates a new policies exporter
 func NewPoliciesExporter(client *nbclient.Client) *PoliciesExporter {
+	return NewPoliciesExporterWithOptions(client, DefaultOptions())
+}
+
+// NewPoliciesExporterWithOptions creates a new policies exporter using the given options
+func NewPoliciesExporterWithOptions(client *nbclient.Client, opts Options) *PoliciesExporter {
 	return &PoliciesExporter{
 		client: client,
+		opts:   opts,
 
 		policiesTotal: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
@@ -79,6 +94,46 @@ func NewPoliciesExporter(client *nbclient.Client) *PoliciesExporter {
 			[]string{"policy_id", "policy_name", "description"},
 		),
 
+		policyRuleEdge: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_policy_rule_edge",
+				Help: "Access granted by an enabled NetBird policy rule from a source group to a destination group (always 1)",
+			},
+			[]string{"policy_id", "rule_id", "source_group_id", "source_group", "destination_group_id", "destination_group", "protocol", "action", "bidirectional"},
+		),
+
+		policyRuleFindings: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_policy_rule_findings",
+				Help: "Risky enabled NetBird policy rules flagged by a policy rule check (always 1)",
+			},
+			[]string{"policy_id", "rule_id", "finding"},
+		),
+
+		policyRulePorts: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_policy_rule_ports",
+				Help: "Number of ports allowed by each enabled NetBird policy rule using TCP or UDP",
+			},
+			[]string{"policy_id", "rule_id", "protocol"},
+		),
+
+		policyRulesByPort: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_policy_rules_by_port",
+				Help: "Number of enabled NetBird policy rules allowing each sensitive port",
+			},
+			[]string{"port", "protocol"},
+		),
+
+		policyRuleWideRange: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_policy_rule_wide_port_range",
+				Help: "Whether an enabled NetBird policy rule allows a port range wider than the configured limit (1 = yes)",
+			},
+			[]string{"policy_id", "rule_id"},
+		),
+
 		scrapeErrorsTotal: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "netbird_policies_scrape_errors_total",
@@ -105,12 +160,22 @@ func (e *PoliciesExporter) Describe(ch chan<- *prometheus.Desc) {
 	e.policyRulesByProto.Describe(ch)
 	e.policyRulesByAction.Describe(ch)
 	e.policyInfo.Describe(ch)
+	e.policyRuleEdge.Describe(ch)
+	e.policyRuleFindings.Describe(ch)
+	e.policyRulePorts.Describe(ch)
+	e.policyRulesByPort.Describe(ch)
+	e.policyRuleWideRange.Describe(ch)
 	e.scrapeErrorsTotal.Describe(ch)
 	e.scrapeDuration.Describe(ch)
 }
 
 // Collect implements prometheus.Collector
 func (e *PoliciesExporter) Collect(ch chan<- prometheus.Metric) {
+	e.collect(ch, newAPISnapshot(e.client))
+}
+
+// collect updates and collects the policy metrics from the objects of one scrape
+func (e *PoliciesExporter) collect(ch chan<- prometheus.Metric, snapshot *apiSnapshot) {
 	timer := prometheus.NewTimer(e.scrapeDuration.WithLabelValues())
 	defer timer.ObserveDuration()
 
@@ -121,11 +186,29 @@ func (e *PoliciesExporter) Collect(ch chan<- prometheus.Metric) {
 	e.policyRulesByProto.Reset()
 	e.policyRulesByAction.Reset()
 	e.policyInfo.Reset()
+	e.policyRuleEdge.Reset()
+	e.policyRuleFindings.Reset()
+	e.policyRulePorts.Reset()
+	e.policyRulesByPort.Reset()
+	e.policyRuleWideRange.Reset()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	policies, err := e.client.Policies.List(ctx)
+	// Routing peers are only needed by the policy rule checks and are fetched
+	// concurrently with the policies
+	var routingGroups map[string]bool
+	var routingDone sync.WaitGroup
+	if len(e.opts.PolicyRuleChecks) > 0 {
+		routingDone.Add(1)
+		go func() {
+			defer routingDone.Done()
+			routingGroups = e.fetchRoutingGroups(ctx, snapshot)
+		}()
+	}
+
+	policies, err := snapshot.policies.get(ctx)
+	routingDone.Wait()
 	if err != nil {
 		logrus.WithError(err).Error("Failed to fetch policies")
 		e.scrapeErrorsTotal.WithLabelValues("fetch_policies").Inc()
@@ -133,6 +216,9 @@ func (e *PoliciesExporter) Collect(ch chan<- prometheus.Metric) {
 	}
 
 	e.updateMetrics(policies)
+	e.updateEdgeMetrics(policies)
+	e.updateFindingMetrics(policies, routingGroups)
+	e.updatePortMetrics(policies)
 
 	// Collect all metrics
 	e.policiesTotal.Collect(ch)
@@ -141,6 +227,11 @@ func (e *PoliciesExporter) Collect(ch chan<- prometheus.Metric) {
 	e.policyRulesByProto.Collect(ch)
 	e.policyRulesByAction.Collect(ch)
 	e.policyInfo.Collect(ch)
+	e.policyRuleEdge.Collect(ch)
+	e.policyRuleFindings.Collect(ch)
+	e.policyRulePorts.Collect(ch)
+	e.policyRulesByPort.Collect(ch)
+	e.policyRuleWideRange.Collect(ch)
 	e.scrapeErrorsTotal.Collect(ch)
 	e.scrapeDuration.Collect(ch)
 }
@@ -201,3 +292,383 @@ func (e *PoliciesExporter) updateMetrics(policies []api.Policy) {
 		"total_rules":    totalRules,
 	}).Debug("Updated policy metrics")
 }
+
+// updateEdgeMetrics exports the access graph of enabled rules in enabled policies
+// as one series per source and destination group pair. Group IDs are exported
+// next to the names, since group names are not unique. Rules that target network
+// resources directly instead of groups have no group edges.
+func (e *PoliciesExporter) updateEdgeMetrics(policies []api.Policy) {
+	edges := 0
+
+	for _, policy := range policies {
+		if !policy.Enabled {
+			continue
+		}
+		policyID := stringValue(policy.Id)
+
+		for _, rule := range policy.Rules {
+			if !rule.Enabled || rule.Sources == nil || rule.Destinations == nil {
+				continue
+			}
+			ruleID := stringValue(rule.Id)
+			bidirectional := strconv.FormatBool(rule.Bidirectional)
+
+			for _, source := range *rule.Sources {
+				for _, destination := range *rule.Destinations {
+					e.policyRuleEdge.WithLabelValues(
+						policyID, ruleID, source.Id, groupDisplayName(source), destination.Id, groupDisplayName(destination),
+						string(rule.Protocol), string(rule.Action), bidirectional,
+					).Set(1)
+					edges++
+				}
+			}
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"policy_edges": edges,
+	}).Debug("Updated policy edge metrics")
+}
+
+// updateFindingMetrics runs the configured policy rule checks against every
+// enabled rule in enabled policies
+func (e *PoliciesExporter) updateFindingMetrics(policies []api.Policy, routingGroups map[string]bool) {
+	findings := 0
+
+	for _, policy := range policies {
+		if !policy.Enabled {
+			continue
+		}
+		policyID := stringValue(policy.Id)
+
+		for _, rule := range policy.Rules {
+			if !rule.Enabled {
+				continue
+			}
+			target := PolicyRuleTarget{Policy: policy, Rule: rule, RoutingGroups: routingGroups}
+
+			for _, check := range e.opts.PolicyRuleChecks {
+				if check.Check(target) {
+					e.policyRuleFindings.WithLabelValues(policyID, stringValue(rule.Id), check.Name).Set(1)
+					findings++
+				}
+			}
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"policy_findings": findings,
+	}).Debug("Updated policy finding metrics")
+}
+
+// updatePortMetrics exports the ports allowed by enabled rules in enabled policies.
+// Rules for protocols without ports, such as ICMP, are skipped.
+func (e *PoliciesExporter) updatePortMetrics(policies []api.Policy) {
+	type portKey struct {
+		port     int
+		protocol string
+	}
+	sensitiveCounts := make(map[portKey]int)
+	wideRules := 0
+
+	for _, policy := range policies {
+		if !policy.Enabled {
+			continue
+		}
+		policyID := stringValue(policy.Id)
+
+		for _, rule := range policy.Rules {
+			ranges, ok := rulePortRanges(rule)
+			if !rule.Enabled || !ok {
+				continue
+			}
+			ruleID := stringValue(rule.Id)
+			protocol := string(rule.Protocol)
+
+			// Unrestricted rules allow the widest range of all
+			allowed := 0
+			wide := 0.0
+			for _, r := range ranges {
+				allowed += r.end - r.start + 1
+				if r.end-r.start+1 > e.opts.PolicyWidePortRange {
+					wide = 1
+				}
+			}
+			if wide == 1 {
+				wideRules++
+			}
+
+			e.policyRulePorts.WithLabelValues(policyID, ruleID, protocol).Set(float64(allowed))
+			e.policyRuleWideRange.WithLabelValues(policyID, ruleID).Set(wide)
+
+			for _, port := range e.opts.PolicySensitivePorts {
+				if portInRanges(port, ranges) {
+					sensitiveCounts[portKey{port, protocol}]++
+				}
+			}
+		}
+	}
+
+	for key, count := range sensitiveCounts {
+		e.policyRulesByPort.WithLabelValues(strconv.Itoa(key.port), key.protocol).Set(float64(count))
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"wide_port_range_rules": wideRules,
+		"sensitive_port_series": len(sensitiveCounts),
+	}).Debug("Updated policy port metrics")
+}
+
+// portRange is an inclusive range of ports
+type portRange struct {
+	start int
+	end   int
+}
+
+// allPorts is the range allowed by TCP and UDP rules without port restrictions
+var allPorts = portRange{start: 1, end: 65535}
+
+// rulePortRanges returns the merged port ranges allowed by a rule, sorted by start
+// port. Rules without ports or port ranges allow every port. The second result is
+// false for protocols that have no ports.
+func rulePortRanges(rule api.PolicyRule) ([]portRange, bool) {
+	switch rule.Protocol {
+	case api.PolicyRuleProtocolTcp, api.PolicyRuleProtocolUdp, api.PolicyRuleProtocolAll:
+	default:
+		return nil, false
+	}
+
+	restricted := false
+	var ranges []portRange
+	if rule.Ports != nil && len(*rule.Ports) > 0 {
+		restricted = true
+		for _, value := range *rule.Ports {
+			port, err := strconv.Atoi(value)
+			if err != nil || port < allPorts.start || port > allPorts.end {
+				continue
+			}
+			ranges = append(ranges, portRange{start: port, end: port})
+		}
+	}
+	if rule.PortRanges != nil && len(*rule.PortRanges) > 0 {
+		restricted = true
+		for _, r := range *rule.PortRanges {
+			if r.Start > r.End {
+				continue
+			}
+			ranges = append(ranges, portRange{start: max(r.Start, allPorts.start), end: min(r.End, allPorts.end)})
+		}
+	}
+	if !restricted {
+		return []portRange{allPorts}, true
+	}
+
+	// Merge overlapping and adjacent ranges so ports are counted once
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].start < ranges[j].start })
+	merged := ranges[:0]
+	for _, r := range ranges {
+		if last := len(merged) - 1; last >= 0 && r.start <= merged[last].end+1 {
+			merged[last].end = max(merged[last].end, r.end)
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged, true
+}
+
+// portInRanges reports whether a port is allowed by any of the ranges
+func portInRanges(port int, ranges []portRange) bool {
+	for _, r := range ranges {
+		if port >= r.start && port <= r.end {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchRoutingGroups returns the IDs of groups containing routing peers of routes
+// or network routers. It returns nil when any source could not be fetched, which
+// disables checks that depend on routing peers for this scrape.
+func (e *PoliciesExporter) fetchRoutingGroups(ctx context.Context, snapshot *apiSnapshot) map[string]bool {
+	var (
+		wg         sync.WaitGroup
+		routes     []api.Route
+		routers    []api.NetworkRouter
+		groups     []api.Group
+		routesErr  error
+		routersErr error
+		groupsErr  error
+	)
+
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		routes, routesErr = snapshot.routes.get(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		routers, routersErr = snapshot.networkRouters.get(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		groups, groupsErr = snapshot.groups.get(ctx)
+	}()
+	wg.Wait()
+
+	failed := false
+	for errorType, err := range map[string]error{
+		"fetch_routes":          routesErr,
+		"fetch_network_routers": routersErr,
+		"fetch_groups":          groupsErr,
+	} {
+		if err != nil {
+			logrus.WithError(err).WithField("error_type", errorType).Warn("Failed to fetch routing peers for policy rule checks")
+			e.scrapeErrorsTotal.WithLabelValues(errorType).Inc()
+			failed = true
+		}
+	}
+	if failed {
+		return nil
+	}
+
+	return routingGroupIDs(routes, routers, groups)
+}
+
+// routingGroupIDs returns the IDs of groups that contain routing peers, either
+// because they are used as peer groups or because they contain a routing peer
+func routingGroupIDs(routes []api.Route, routers []api.NetworkRouter, groups []api.Group) map[string]bool {
+	routingGroups := make(map[string]bool)
+	routingPeers := make(map[string]bool)
+
+	addRouting := func(peer *string, peerGroups *[]string) {
+		if peer != nil && *peer != "" {
+			routingPeers[*peer] = true
+		}
+		if peerGroups != nil {
+			for _, groupID := range *peerGroups {
+				routingGroups[groupID] = true
+			}
+		}
+	}
+	for _, route := range routes {
+		addRouting(route.Peer, route.PeerGroups)
+	}
+	for _, router := range routers {
+		addRouting(router.Peer, router.PeerGroups)
+	}
+
+	for _, group := range groups {
+		for _, peer := range group.Peers {
+			if routingPeers[peer.Id] {
+				routingGroups[group.Id] = true
+				break
+			}
+		}
+	}
+
+	return routingGroups
+}
+
+// PolicyRuleTarget is an enabled policy rule evaluated by a policy rule check
+type PolicyRuleTarget struct {
+	Policy api.Policy
+	Rule   api.PolicyRule
+
+	// RoutingGroups holds the IDs of groups containing routing peers; nil when unknown
+	RoutingGroups map[string]bool
+}
+
+// PolicyRuleCheck flags risky policy rules; Name is exported as the finding label
+type PolicyRuleCheck struct {
+	Name  string
+	Check func(target PolicyRuleTarget) bool
+}
+
+// DefaultPolicyRuleChecks returns the built-in policy rule checks
+func DefaultPolicyRuleChecks() []PolicyRuleCheck {
+	return []PolicyRuleCheck{
+		{Name: "all_to_all", Check: checkAllToAll},
+		{Name: "all_protocols_no_ports", Check: checkAllProtocolsNoPorts},
+		{Name: "routing_peer_destination", Check: checkRoutingPeerDestination},
+		{Name: "no_posture_checks", Check: checkNoPostureChecks},
+	}
+}
+
+// SelectPolicyRuleChecks returns the built-in checks with the given names
+func SelectPolicyRuleChecks(names []string) ([]PolicyRuleCheck, error) {
+	available := make(map[string]PolicyRuleCheck)
+	for _, check := range DefaultPolicyRuleChecks() {
+		available[check.Name] = check
+	}
+
+	checks := make([]PolicyRuleCheck, 0, len(names))
+	for _, name := range names {
+		check, ok := available[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown policy rule check %q", name)
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+// checkAllToAll flags rules from the "All" group to the "All" group
+func checkAllToAll(target PolicyRuleTarget) bool {
+	return containsAllGroup(target.Rule.Sources) && containsAllGroup(target.Rule.Destinations)
+}
+
+// checkAllProtocolsNoPorts flags rules allowing every protocol without port restrictions
+func checkAllProtocolsNoPorts(target PolicyRuleTarget) bool {
+	rule := target.Rule
+	return rule.Protocol == api.PolicyRuleProtocolAll &&
+		(rule.Ports == nil || len(*rule.Ports) == 0) &&
+		(rule.PortRanges == nil || len(*rule.PortRanges) == 0)
+}
+
+// checkRoutingPeerDestination flags accept rules whose destinations contain routing
+// peers, which grants access to the networks behind them
+func checkRoutingPeerDestination(target PolicyRuleTarget) bool {
+	if target.Rule.Action != api.PolicyRuleActionAccept || target.Rule.Destinations == nil {
+		return false
+	}
+	for _, destination := range *target.Rule.Destinations {
+		if target.RoutingGroups[destination.Id] {
+			return true
+		}
+	}
+	return false
+}
+
+// checkNoPostureChecks flags rules of policies without source posture checks
+func checkNoPostureChecks(target PolicyRuleTarget) bool {
+	return len(target.Policy.SourcePostureChecks) == 0
+}
+
+// containsAllGroup reports whether an optional list of groups contains the "All" group
+func containsAllGroup(groups *[]api.GroupMinimum) bool {
+	if groups == nil {
+		return false
+	}
+	for _, group := range *groups {
+		if group.Name == allGroupName {
+			return true
+		}
+	}
+	return false
+}
+
+// groupDisplayName returns the name of a group, falling back to its ID
+func groupDisplayName(group api.GroupMinimum) string {
+	if group.Name != "" {
+		return group.Name
+	}
+	return group.Id
+}
+
+// stringValue dereferences an optional string
+func stringValue(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
@@ -305,3 +305,261 @@ func TestPoliciesExporter_MetricsReset(t *testing.T) {
 		}
 	}
 }
+
+func TestPoliciesExporter_Edges(t *testing.T) {
+	client := nbclient.New("https://api.netbird.io", "test-token")
+	exporter := NewPoliciesExporter(client)
+
+	policyID := "policy1"
+	ruleID := "rule1"
+	disabledRuleID := "rule2"
+	exporter.updateEdgeMetrics([]api.Policy{
+		{
+			Id:      &policyID,
+			Name:    "devs-to-servers",
+			Enabled: true,
+			Rules: []api.PolicyRule{
+				{
+					Id:            &ruleID,
+					Enabled:       true,
+					Action:        api.PolicyRuleActionAccept,
+					Protocol:      api.PolicyRuleProtocolTcp,
+					Bidirectional: false,
+					Sources:       &[]api.GroupMinimum{{Id: "devs", Name: "Developers"}, {Id: "ops"}, {Id: "devs-eu", Name: "Developers"}},
+					Destinations:  &[]api.GroupMinimum{{Id: "servers", Name: "Servers"}},
+				},
+				{
+					Id:           &disabledRuleID,
+					Enabled:      false,
+					Sources:      &[]api.GroupMinimum{{Id: "devs", Name: "Developers"}},
+					Destinations: &[]api.GroupMinimum{{Id: "db", Name: "Databases"}},
+				},
+			},
+		},
+	})
+
+	// Groups sharing a name keep separate edges through their IDs
+	edgeTests := []struct {
+		sourceID string
+		source   string
+		found    bool
+	}{
+		{"devs", "Developers", true},
+		{"devs-eu", "Developers", true},
+		{"ops", "ops", true},
+	}
+	for _, tt := range edgeTests {
+		_, found := gatherMetricValue(t, exporter.policyRuleEdge, "netbird_policy_rule_edge", map[string]string{
+			"policy_id": "policy1", "rule_id": "rule1", "source_group_id": tt.sourceID, "source_group": tt.source,
+			"destination_group_id": "servers", "destination_group": "Servers",
+			"protocol": "tcp", "action": "accept", "bidirectional": "false",
+		})
+		if found != tt.found {
+			t.Errorf("Expected edge from %s to Servers found=%v, got %v", tt.source, tt.found, found)
+		}
+	}
+
+	if _, found := gatherMetricValue(t, exporter.policyRuleEdge, "netbird_policy_rule_edge", map[string]string{"rule_id": "rule2"}); found {
+		t.Error("Expected no edges for a disabled rule")
+	}
+}
+
+func TestPoliciesExporter_Findings(t *testing.T) {
+	client := nbclient.New("https://api.netbird.io", "test-token")
+	exporter := NewPoliciesExporter(client)
+
+	policyID := "policy1"
+	postureID := "policy2"
+	openRuleID := "open"
+	routerRuleID := "router"
+	safeRuleID := "safe"
+	all := api.GroupMinimum{Id: "all", Name: "All"}
+	exporter.updateFindingMetrics([]api.Policy{
+		{
+			Id:      &policyID,
+			Enabled: true,
+			Rules: []api.PolicyRule{
+				{
+					Id:           &openRuleID,
+					Enabled:      true,
+					Action:       api.PolicyRuleActionAccept,
+					Protocol:     api.PolicyRuleProtocolAll,
+					Sources:      &[]api.GroupMinimum{all},
+					Destinations: &[]api.GroupMinimum{all},
+				},
+			},
+		},
+		{
+			Id:                  &postureID,
+			Enabled:             true,
+			SourcePostureChecks: []string{"check1"},
+			Rules: []api.PolicyRule{
+				{
+					Id:           &routerRuleID,
+					Enabled:      true,
+					Action:       api.PolicyRuleActionAccept,
+					Protocol:     api.PolicyRuleProtocolTcp,
+					Ports:        &[]string{"443"},
+					Sources:      &[]api.GroupMinimum{{Id: "devs", Name: "Developers"}},
+					Destinations: &[]api.GroupMinimum{{Id: "routers", Name: "Routers"}},
+				},
+				{
+					Id:           &safeRuleID,
+					Enabled:      true,
+					Action:       api.PolicyRuleActionAccept,
+					Protocol:     api.PolicyRuleProtocolTcp,
+					Ports:        &[]string{"443"},
+					Sources:      &[]api.GroupMinimum{{Id: "devs", Name: "Developers"}},
+					Destinations: &[]api.GroupMinimum{{Id: "servers", Name: "Servers"}},
+				},
+			},
+		},
+	}, map[string]bool{"routers": true})
+
+	findingTests := []struct {
+		policyID string
+		ruleID   string
+		finding  string
+		found    bool
+	}{
+		{"policy1", "open", "all_to_all", true},
+		{"policy1", "open", "all_protocols_no_ports", true},
+		{"policy1", "open", "no_posture_checks", true},
+		{"policy1", "open", "routing_peer_destination", false},
+		{"policy2", "router", "routing_peer_destination", true},
+		{"policy2", "router", "no_posture_checks", false},
+		{"policy2", "router", "all_to_all", false},
+	}
+	for _, tt := range findingTests {
+		_, found := gatherMetricValue(t, exporter.policyRuleFindings, "netbird_policy_rule_findings", map[string]string{
+			"policy_id": tt.policyID, "rule_id": tt.ruleID, "finding": tt.finding,
+		})
+		if found != tt.found {
+			t.Errorf("Expected finding %s on rule %s found=%v, got %v", tt.finding, tt.ruleID, tt.found, found)
+		}
+	}
+
+	if _, found := gatherMetricValue(t, exporter.policyRuleFindings, "netbird_policy_rule_findings", map[string]string{"rule_id": "safe"}); found {
+		t.Error("Expected no findings for a restricted rule")
+	}
+}
+
+func TestRoutingGroupIDs(t *testing.T) {
+	routerPeer := "peer1"
+	routes := []api.Route{{PeerGroups: &[]string{"route-routers"}}}
+	routers := []api.NetworkRouter{{Peer: &routerPeer}}
+	groups := []api.Group{
+		{Id: "with-router", Peers: []api.PeerMinimum{{Id: "peer2"}, {Id: "peer1"}}},
+		{Id: "without-router", Peers: []api.PeerMinimum{{Id: "peer2"}}},
+	}
+
+	routingGroups := routingGroupIDs(routes, routers, groups)
+	if !routingGroups["route-routers"] || !routingGroups["with-router"] || routingGroups["without-router"] {
+		t.Errorf("Unexpected routing groups: %v", routingGroups)
+	}
+}
+
+func TestSelectPolicyRuleChecks(t *testing.T) {
+	checks, err := SelectPolicyRuleChecks([]string{"all_to_all", "no_posture_checks"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(checks) != 2 || checks[0].Name != "all_to_all" || checks[1].Name != "no_posture_checks" {
+		t.Errorf("Unexpected checks: %v", checks)
+	}
+
+	if _, err := SelectPolicyRuleChecks([]string{"unknown"}); err == nil {
+		t.Error("Expected error for an unknown check")
+	}
+}
+
+func TestPoliciesExporter_Ports(t *testing.T) {
+	client := nbclient.New("https://api.netbird.io", "test-token")
+	exporter := NewPoliciesExporter(client)
+
+	policyID := "policy1"
+	sshRuleID := "ssh"
+	rangeRuleID := "range"
+	openRuleID := "open"
+	icmpRuleID := "icmp"
+	exporter.updatePortMetrics([]api.Policy{
+		{
+			Id:      &policyID,
+			Enabled: true,
+			Rules: []api.PolicyRule{
+				{
+					Id:         &sshRuleID,
+					Enabled:    true,
+					Protocol:   api.PolicyRuleProtocolTcp,
+					Ports:      &[]string{"22", "443", "8080"},
+					PortRanges: &[]api.RulePortRange{{Start: 8000, End: 8100}},
+				},
+				{
+					Id:         &rangeRuleID,
+					Enabled:    true,
+					Protocol:   api.PolicyRuleProtocolUdp,
+					PortRanges: &[]api.RulePortRange{{Start: 10000, End: 20000}},
+				},
+				{Id: &openRuleID, Enabled: true, Protocol: api.PolicyRuleProtocolTcp},
+				{Id: &icmpRuleID, Enabled: true, Protocol: api.PolicyRuleProtocolIcmp},
+			},
+		},
+	})
+
+	portTests := []struct {
+		ruleID   string
+		protocol string
+		expected float64
+	}{
+		{"ssh", "tcp", 103}, // 8080 lies within 8000-8100 and is counted once
+		{"range", "udp", 10001},
+		{"open", "tcp", 65535},
+	}
+	for _, tt := range portTests {
+		value, found := gatherMetricValue(t, exporter.policyRulePorts, "netbird_policy_rule_ports", map[string]string{
+			"policy_id": "policy1", "rule_id": tt.ruleID, "protocol": tt.protocol,
+		})
+		if !found || value != tt.expected {
+			t.Errorf("Expected %v ports for rule %s, got %v (found=%v)", tt.expected, tt.ruleID, value, found)
+		}
+	}
+	if _, found := gatherMetricValue(t, exporter.policyRulePorts, "netbird_policy_rule_ports", map[string]string{"rule_id": "icmp"}); found {
+		t.Error("Expected no port series for an ICMP rule")
+	}
+
+	sensitiveTests := []struct {
+		port     string
+		protocol string
+		expected float64
+		found    bool
+	}{
+		{"22", "tcp", 2, true},    // explicit port and unrestricted rule
+		{"3389", "tcp", 1, true},  // unrestricted rule only
+		{"5432", "udp", 0, false}, // outside the UDP range
+	}
+	for _, tt := range sensitiveTests {
+		value, found := gatherMetricValue(t, exporter.policyRulesByPort, "netbird_policy_rules_by_port", map[string]string{
+			"port": tt.port, "protocol": tt.protocol,
+		})
+		if found != tt.found || value != tt.expected {
+			t.Errorf("Expected %v rules allowing %s/%s, got %v (found=%v)", tt.expected, tt.port, tt.protocol, value, found)
+		}
+	}
+
+	wideTests := []struct {
+		ruleID   string
+		expected float64
+	}{
+		{"ssh", 0},
+		{"range", 1},
+		{"open", 1},
+	}
+	for _, tt := range wideTests {
+		value, found := gatherMetricValue(t, exporter.policyRuleWideRange, "netbird_policy_rule_wide_port_range", map[string]string{
+			"policy_id": "policy1", "rule_id": tt.ruleID,
+		})
+		if !found || value != tt.expected {
+			t.Errorf("Expected wide port range %v for rule %s, got %v (found=%v)", tt.expected, tt.ruleID, value, found)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package exporters
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// LabelPrivacyMode controls how the values of a metric label are exposed
+type LabelPrivacyMode string
+
+const (
+	// LabelPrivacyKeep exposes label values unchanged
+	LabelPrivacyKeep LabelPrivacyMode = "keep"
+	// LabelPrivacyDrop removes the label from every series
+	LabelPrivacyDrop LabelPrivacyMode = "drop"
+	// LabelPrivacyHash replaces label values with a keyed HMAC-SHA256 hash
+	LabelPrivacyHash LabelPrivacyMode = "hash"
+)
+
+// labelHashLength is the number of hex characters kept from each label hash
+const labelHashLength = 16
+
+// privacyMetricPrefix limits label rewriting to the exporter's own metrics
+const privacyMetricPrefix = "netbird_"
+
+// privacyLabels are the labels identifying people or devices that privacy
+// rules can be configured for
+var privacyLabels = []string{
+	"user_id", "user_email", "user_name", "peer_id", "peer_name",
+	"hostname", "serial_number", "dns_label", "token_name",
+}
+
+// kindValueLabels maps the kind of metrics carrying values of several labels in a
+// generic "value" label to the label whose privacy rule applies to that value
+var kindValueLabels = map[string]map[string]string{
+	"netbird_peers_duplicate_value": {
+		"hostname":  "hostname",
+		"dns_label": "dns_label",
+		"serial":    "serial_number",
+	},
+}
+
+// maxMergedSuffixes are metric name suffixes of values that cannot be summed,
+// such as timestamps, ages, ratios and info series. When series of these metrics
+// are merged after a label is dropped, the largest value is kept.
+var maxMergedSuffixes = []string{"_timestamp", "_seconds", "_ratio", "_info"}
+
+// ParseLabelPrivacy parses label=mode rules. Malformed rules and labels that
+// cannot carry personal data are rejected, so a typo never leaves data exposed.
+func ParseLabelPrivacy(rules []string) (map[string]LabelPrivacyMode, error) {
+	modes := make(map[string]LabelPrivacyMode, len(rules))
+	for _, rule := range rules {
+		label, value, ok := strings.Cut(rule, "=")
+		label, value = strings.TrimSpace(label), strings.TrimSpace(value)
+		if !ok || label == "" {
+			return nil, fmt.Errorf("invalid privacy rule %q: must be label=mode", rule)
+		}
+		if !slices.Contains(privacyLabels, label) {
+			return nil, fmt.Errorf("unsupported privacy label %q: must be one of %s", label, strings.Join(privacyLabels, ", "))
+		}
+		switch mode := LabelPrivacyMode(strings.ToLower(value)); mode {
+		case LabelPrivacyKeep, LabelPrivacyDrop, LabelPrivacyHash:
+			modes[label] = mode
+		default:
+			return nil, fmt.Errorf("invalid privacy mode %q for label %q: must be one of keep, drop, hash", value, label)
+		}
+	}
+	return modes, nil
+}
+
+// LabelPrivacy rewrites personally identifiable label values of the exported
+// metrics. The same key is used for every metric so hashed values still join.
+type LabelPrivacy struct {
+	modes map[string]LabelPrivacyMode
+	key   []byte
+}
+
+// NewLabelPrivacy creates a label rewriter; a key is required when any label is hashed
+func NewLabelPrivacy(modes map[string]LabelPrivacyMode, key string) (*LabelPrivacy, error) {
+	for label, mode := range modes {
+		if mode == LabelPrivacyHash && key == "" {
+			return nil, fmt.Errorf("label %q is hashed but no privacy key is configured", label)
+		}
+	}
+	return &LabelPrivacy{modes: modes, key: []byte(key)}, nil
+}
+
+// Gatherer wraps a gatherer so that every gathered NetBird metric is rewritten
+func (p *LabelPrivacy) Gatherer(gatherer prometheus.Gatherer) prometheus.Gatherer {
+	return prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		families, err := gatherer.Gather()
+		for _, family := range families {
+			if strings.HasPrefix(family.GetName(), privacyMetricPrefix) {
+				p.rewriteFamily(family)
+			}
+		}
+		return families, err
+	})
+}
+
+// hash returns the truncated keyed hash of a label value; empty values stay empty
+func (p *LabelPrivacy) hash(value string) string {
+	if value == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, p.key)
+	_, _ = mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))[:labelHashLength]
+}
+
+// rewriteFamily applies the label rules to every series of a metric family.
+// Series that become identical after dropping a label are merged.
+func (p *LabelPrivacy) rewriteFamily(family *dto.MetricFamily) {
+	merged := make(map[string]*dto.Metric, len(family.Metric))
+	metrics := family.Metric[:0]
+	kindLabels := kindValueLabels[family.GetName()]
+	keepMax := hasAnySuffix(family.GetName(), maxMergedSuffixes)
+
+	for _, metric := range family.Metric {
+		valueLabel := "value"
+		if kindLabels != nil {
+			valueLabel = kindLabels[labelValue(metric.Label, "kind")]
+		}
+
+		labels := metric.Label[:0]
+		for _, label := range metric.Label {
+			name := label.GetName()
+			if kindLabels != nil && name == "value" {
+				name = valueLabel
+			}
+
+			switch p.modes[name] {
+			case LabelPrivacyDrop:
+				continue
+			case LabelPrivacyHash:
+				label.Value = stringPtr(p.hash(label.GetValue()))
+			}
+			labels = append(labels, label)
+		}
+		metric.Label = labels
+
+		key := labelPairsKey(labels)
+		if existing, ok := merged[key]; ok {
+			mergeMetric(existing, metric, keepMax)
+			continue
+		}
+		merged[key] = metric
+		metrics = append(metrics, metric)
+	}
+
+	sort.Slice(metrics, func(i, j int) bool {
+		return labelPairsKey(metrics[i].Label) < labelPairsKey(metrics[j].Label)
+	})
+	family.Metric = metrics
+}
+
+// mergeMetric combines the value of a series into another with the same labels.
+// Counters are summed; gauges and untyped values are summed, or the largest is
+// kept when keepMax is set. For other types the first series is kept.
+func mergeMetric(into, from *dto.Metric, keepMax bool) {
+	combine := func(a, b float64) float64 {
+		if keepMax {
+			return max(a, b)
+		}
+		return a + b
+	}
+
+	switch {
+	case into.Gauge != nil && from.Gauge != nil:
+		into.Gauge.Value = float64Ptr(combine(into.Gauge.GetValue(), from.Gauge.GetValue()))
+	case into.Counter != nil && from.Counter != nil:
+		into.Counter.Value = float64Ptr(into.Counter.GetValue() + from.Counter.GetValue())
+	case into.Untyped != nil && from.Untyped != nil:
+		into.Untyped.Value = float64Ptr(combine(into.Untyped.GetValue(), from.Untyped.GetValue()))
+	}
+}
+
+// labelValue returns the value of a label, or an empty string when it is missing
+func labelValue(labels []*dto.LabelPair, name string) string {
+	for _, label := range labels {
+		if label.GetName() == name {
+			return label.GetValue()
+		}
+	}
+	return ""
+}
+
+// hasAnySuffix reports whether a name ends with any of the suffixes
+func hasAnySuffix(name string, suffixes []string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// labelPairsKey builds a comparable key from label pairs, which are sorted by name
+func labelPairsKey(labels []*dto.LabelPair) string {
+	var key strings.Builder
+	for _, label := range labels {
+		key.WriteString(label.GetName())
+		key.WriteByte(0)
+		key.WriteString(label.GetValue())
+		key.WriteByte(0)
+	}
+	return key.String()
+}
+
+func stringPtr(value string) *string {
+	return &value
+}
+
+func float64Ptr(value float64) *float64 {
+	return &value
+}
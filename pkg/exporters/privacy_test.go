@@ -0,0 +1,182 @@
+package exporters
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	nbclient "github.com/netbirdio/netbird/shared/management/client/rest"
+	"github.com/netbirdio/netbird/shared/management/http/api"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestParseLabelPrivacy(t *testing.T) {
+	modes, err := ParseLabelPrivacy([]string{"user_email=hash", "user_name=DROP", " user_id = keep"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if modes["user_email"] != LabelPrivacyHash || modes["user_name"] != LabelPrivacyDrop || modes["user_id"] != LabelPrivacyKeep {
+		t.Errorf("Unexpected modes: %v", modes)
+	}
+
+	invalidRules := []struct {
+		name string
+		rule string
+	}{
+		{"invalid mode", "hostname=mask"},
+		{"missing separator", "user_email:hash"},
+		{"missing label", "=hash"},
+		{"unknown label", "user_emial=hash"},
+	}
+	for _, tt := range invalidRules {
+		if _, err := ParseLabelPrivacy([]string{"user_name=drop", tt.rule}); err == nil {
+			t.Errorf("Expected error for %s in rule %q", tt.name, tt.rule)
+		}
+	}
+}
+
+func TestNewLabelPrivacy_RequiresKeyForHash(t *testing.T) {
+	if _, err := NewLabelPrivacy(map[string]LabelPrivacyMode{"user_email": LabelPrivacyHash}, ""); err == nil {
+		t.Error("Expected error when hashing without a key")
+	}
+	if _, err := NewLabelPrivacy(map[string]LabelPrivacyMode{"user_email": LabelPrivacyDrop}, ""); err != nil {
+		t.Errorf("Expected dropping to work without a key, got %v", err)
+	}
+}
+
+func TestLabelPrivacy_Gatherer(t *testing.T) {
+	registry := prometheus.NewRegistry()
+
+	userInfo := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "netbird_test_user_info", Help: "test"}, []string{"user_id", "user_email", "user_name"})
+	userInfo.WithLabelValues("user1", "alice@example.com", "Alice").Set(1)
+	userInfo.WithLabelValues("user2", "bob@example.com", "Bob").Set(1)
+
+	userPeers := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "netbird_test_user_peers", Help: "test"}, []string{"user_email", "connected"})
+	userPeers.WithLabelValues("alice@example.com", "true").Set(2)
+	userPeers.WithLabelValues("bob@example.com", "true").Set(1)
+
+	byName := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "netbird_test_by_name", Help: "test"}, []string{"user_name"})
+	byName.WithLabelValues("Alice").Set(3)
+	byName.WithLabelValues("Bob").Set(4)
+
+	lastLogin := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "netbird_test_last_login_timestamp", Help: "test"}, []string{"user_name"})
+	lastLogin.WithLabelValues("Alice").Set(100)
+	lastLogin.WithLabelValues("Bob").Set(200)
+
+	other := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "other_metric", Help: "test"}, []string{"user_name"})
+	other.WithLabelValues("Alice").Set(1)
+
+	registry.MustRegister(userInfo, userPeers, byName, lastLogin, other)
+
+	privacy, err := NewLabelPrivacy(map[string]LabelPrivacyMode{"user_email": LabelPrivacyHash, "user_name": LabelPrivacyDrop}, "secret")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	families, err := privacy.Gatherer(registry).Gather()
+	if err != nil {
+		t.Fatalf("Failed to gather metrics: %v", err)
+	}
+
+	byFamily := make(map[string]*dto.MetricFamily)
+	for _, family := range families {
+		byFamily[family.GetName()] = family
+	}
+
+	aliceHash := privacy.hash("alice@example.com")
+	if aliceHash == "alice@example.com" || len(aliceHash) != labelHashLength {
+		t.Fatalf("Unexpected hash %q", aliceHash)
+	}
+
+	// Hashed values are identical across metrics so they can still be joined
+	for _, name := range []string{"netbird_test_user_info", "netbird_test_user_peers"} {
+		found := false
+		for _, metric := range byFamily[name].GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "user_name" {
+					t.Errorf("Expected user_name to be dropped from %s", name)
+				}
+				if label.GetName() == "user_email" && label.GetValue() == aliceHash {
+					found = true
+				}
+			}
+		}
+		if !found {
+			t.Errorf("Expected hashed user_email in %s", name)
+		}
+	}
+
+	// Series that collide once a label is dropped are summed
+	merged := byFamily["netbird_test_by_name"].GetMetric()
+	if len(merged) != 1 || merged[0].GetGauge().GetValue() != 7 {
+		t.Errorf("Expected a single merged series with value 7, got %v", merged)
+	}
+
+	// Timestamps cannot be summed, so the latest one is kept
+	latest := byFamily["netbird_test_last_login_timestamp"].GetMetric()
+	if len(latest) != 1 || latest[0].GetGauge().GetValue() != 200 {
+		t.Errorf("Expected a single merged timestamp with value 200, got %v", latest)
+	}
+
+	// Metrics outside the exporter are left untouched
+	if labels := byFamily["other_metric"].GetMetric()[0].GetLabel(); len(labels) != 1 || labels[0].GetValue() != "Alice" {
+		t.Errorf("Expected other metrics to be unchanged, got %v", labels)
+	}
+}
+
+func TestLabelPrivacy_Gatherer_PeersExporter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/peers" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode([]api.Peer{
+			{Id: "peer1", Name: "laptop", Hostname: "alice-laptop", DnsLabel: "laptop-a", SerialNumber: "SN-ALICE"},
+			{Id: "peer2", Name: "laptop", Hostname: "alice-laptop", DnsLabel: "laptop-b", SerialNumber: "SN-ALICE"},
+		}); err != nil {
+			t.Errorf("Failed to encode peers: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(NewPeersExporter(nbclient.New(server.URL, "test-token")))
+
+	modes, err := ParseLabelPrivacy([]string{"hostname=hash", "serial_number=drop"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	privacy, err := NewLabelPrivacy(modes, "secret")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	families, err := privacy.Gatherer(registry).Gather()
+	if err != nil {
+		t.Fatalf("Failed to gather metrics: %v", err)
+	}
+
+	hashedHostname := false
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			kind := labelValue(metric.GetLabel(), "kind")
+			for _, label := range metric.GetLabel() {
+				if value := label.GetValue(); value == "alice-laptop" || value == "SN-ALICE" {
+					t.Errorf("Expected %q to be rewritten in %s, got label %s", value, family.GetName(), label.GetName())
+				}
+				if family.GetName() == "netbird_peers_duplicate_value" && kind == "serial" && label.GetName() == "value" {
+					t.Error("Expected the serial number value label to be dropped")
+				}
+				if family.GetName() == "netbird_peers_duplicate_value" && kind == "hostname" && label.GetValue() == privacy.hash("alice-laptop") {
+					hashedHostname = true
+				}
+			}
+		}
+	}
+	if !hashedHostname {
+		t.Error("Expected the duplicate hostname value to be hashed")
+	}
+}
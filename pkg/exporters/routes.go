@@ -91,6 +91,11 @@ func (e *RoutesExporter) Describe(ch chan<- *prometheus.Desc) {
 
 // Collect implements prometheus.Collector
 func (e *RoutesExporter) Collect(ch chan<- prometheus.Metric) {
+	e.collect(ch, newAPISnapshot(e.client))
+}
+
+// collect updates and collects the route metrics from the objects of one scrape
+func (e *RoutesExporter) collect(ch chan<- prometheus.Metric, snapshot *apiSnapshot) {
 	timer := prometheus.NewTimer(e.scrapeDuration.WithLabelValues())
 	defer timer.ObserveDuration()
 
@@ -103,7 +108,7 @@ func (e *RoutesExporter) Collect(ch chan<- prometheus.Metric) {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	routes, err := e.client.Routes.List(ctx)
+	routes, err := snapshot.routes.get(ctx)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to fetch routes")
 		e.scrapeErrorsTotal.WithLabelValues("fetch_routes").Inc()
@@ -3,6 +3,7 @@ package exporters
 import (
 	"context"
 	"strconv"
+	"sync"
 	"time"
 
 	nbclient "github.com/netbirdio/netbird/shared/management/client/rest"
@@ -14,6 +15,7 @@ import (
 // SetupKeysExporter handles setup-key-specific metrics collection
 type SetupKeysExporter struct {
 	client *nbclient.Client
+	opts   Options
 
 	// Prometheus metrics for setup keys
 	setupKeysTotal     *prometheus.GaugeVec
@@ -26,14 +28,57 @@ type SetupKeysExporter struct {
 	setupKeyLastUsed   *prometheus.GaugeVec
 	setupKeyInfo       *prometheus.GaugeVec
 	setupKeyAutoGroups *prometheus.GaugeVec
+	setupKeyExpiresIn  *prometheus.GaugeVec
+	setupKeyRemaining  *prometheus.GaugeVec
+	setupKeysExpiring  *prometheus.GaugeVec
+	setupKeyAutoGroup  *prometheus.GaugeVec
+	setupKeyDangling   *prometheus.GaugeVec
+	setupKeyUses       *prometheus.CounterVec
+	setupKeyEnrollRate *prometheus.GaugeVec
+	setupKeyUsageSpike *prometheus.GaugeVec
 	scrapeErrorsTotal  *prometheus.CounterVec
 	scrapeDuration     *prometheus.HistogramVec
+
+	// Usage observed for each setup key across polls
+	usageMu sync.Mutex
+	usage   map[string]*setupKeyUsage
+}
+
+// setupKeyUsageWindow is the period over which enrollment rates are computed
+const setupKeyUsageWindow = time.Hour
+
+// usageSample is the use count of a setup key observed at a point in time
+type usageSample struct {
+	at        time.Time
+	usedTimes int
+}
+
+// setupKeyUsage holds the recent use counts of a setup key, oldest first
+type setupKeyUsage struct {
+	samples []usageSample
+}
+
+// setupKeyExpiryWindows are the periods reported by netbird_setup_keys_expiring
+var setupKeyExpiryWindows = []struct {
+	label    string
+	duration time.Duration
+}{
+	{"24h", 24 * time.Hour},
+	{"7d", 7 * 24 * time.Hour},
+	{"30d", 30 * 24 * time.Hour},
 }
 
 // NewSetupKeysExporter creates a new setup keys exporter
 func NewSetupKeysExporter(client *nbclient.Client) *SetupKeysExporter {
+	return NewSetupKeysExporterWithOptions(client, DefaultOptions())
+}
+
+// NewSetupKeysExporterWithOptions creates a new setup keys exporter using the given options
+func NewSetupKeysExporterWithOptions(client *nbclient.Client, opts Options) *SetupKeysExporter {
 	return &SetupKeysExporter{
 		client: client,
+		opts:   opts,
+		usage:  make(map[string]*setupKeyUsage),
 
 		setupKeysTotal: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
@@ -115,6 +160,70 @@ func NewSetupKeysExporter(client *nbclient.Client) *SetupKeysExporter {
 			[]string{"key_id", "key_name"},
 		),
 
+		setupKeyExpiresIn: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_setup_key_expires_in_seconds",
+				Help: "Seconds until a valid, non-revoked NetBird setup key expires",
+			},
+			[]string{"key_id", "key_name"},
+		),
+
+		setupKeyRemaining: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_setup_key_remaining_uses",
+				Help: "Remaining uses of a valid, non-revoked NetBird setup key with a usage limit",
+			},
+			[]string{"key_id", "key_name"},
+		),
+
+		setupKeysExpiring: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_setup_keys_expiring",
+				Help: "Number of valid, non-revoked NetBird setup keys expiring within each period",
+			},
+			[]string{"within"},
+		),
+
+		setupKeyAutoGroup: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_setup_key_auto_group",
+				Help: "Groups auto-assigned by each NetBird setup key, with an empty group name for deleted groups (always 1)",
+			},
+			[]string{"key_id", "key_name", "group_id", "group_name"},
+		),
+
+		setupKeyDangling: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_setup_key_dangling_auto_groups",
+				Help: "Number of auto-groups of each NetBird setup key that reference deleted groups",
+			},
+			[]string{"key_id", "key_name"},
+		),
+
+		setupKeyUses: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "netbird_setup_key_uses_total",
+				Help: "Total number of peer enrollments with a NetBird setup key, tracked across polls",
+			},
+			[]string{"key_id", "key_name"},
+		),
+
+		setupKeyEnrollRate: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_setup_key_enrollments_rate",
+				Help: "Peer enrollments per hour with a NetBird setup key over the last hour",
+			},
+			[]string{"key_id", "key_name"},
+		),
+
+		setupKeyUsageSpike: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_setup_key_usage_spike",
+				Help: "Whether a reusable NetBird setup key was used more than the configured threshold within the last hour (1 = yes)",
+			},
+			[]string{"key_id", "key_name"},
+		),
+
 		scrapeErrorsTotal: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "netbird_setup_keys_scrape_errors_total",
@@ -145,12 +254,25 @@ func (e *SetupKeysExporter) Describe(ch chan<- *prometheus.Desc) {
 	e.setupKeyLastUsed.Describe(ch)
 	e.setupKeyInfo.Describe(ch)
 	e.setupKeyAutoGroups.Describe(ch)
+	e.setupKeyExpiresIn.Describe(ch)
+	e.setupKeyRemaining.Describe(ch)
+	e.setupKeysExpiring.Describe(ch)
+	e.setupKeyAutoGroup.Describe(ch)
+	e.setupKeyDangling.Describe(ch)
+	e.setupKeyUses.Describe(ch)
+	e.setupKeyEnrollRate.Describe(ch)
+	e.setupKeyUsageSpike.Describe(ch)
 	e.scrapeErrorsTotal.Describe(ch)
 	e.scrapeDuration.Describe(ch)
 }
 
 // Collect implements prometheus.Collector
 func (e *SetupKeysExporter) Collect(ch chan<- prometheus.Metric) {
+	e.collect(ch, newAPISnapshot(e.client))
+}
+
+// collect updates and collects the setup key metrics from the objects of one scrape
+func (e *SetupKeysExporter) collect(ch chan<- prometheus.Metric, snapshot *apiSnapshot) {
 	timer := prometheus.NewTimer(e.scrapeDuration.WithLabelValues())
 	defer timer.ObserveDuration()
 
@@ -165,11 +287,28 @@ func (e *SetupKeysExporter) Collect(ch chan<- prometheus.Metric) {
 	e.setupKeyLastUsed.Reset()
 	e.setupKeyInfo.Reset()
 	e.setupKeyAutoGroups.Reset()
+	e.setupKeyExpiresIn.Reset()
+	e.setupKeyRemaining.Reset()
+	e.setupKeysExpiring.Reset()
+	e.setupKeyAutoGroup.Reset()
+	e.setupKeyDangling.Reset()
+	e.setupKeyEnrollRate.Reset()
+	e.setupKeyUsageSpike.Reset()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	setupKeys, err := e.client.SetupKeys.List(ctx)
+	// Fetch groups alongside the setup keys to resolve auto-group names
+	var groups []api.Group
+	var groupsErr error
+	groupsDone := make(chan struct{})
+	go func() {
+		defer close(groupsDone)
+		groups, groupsErr = snapshot.groups.get(ctx)
+	}()
+
+	setupKeys, err := snapshot.setupKeys.get(ctx)
+	<-groupsDone
 	if err != nil {
 		logrus.WithError(err).Error("Failed to fetch setup keys")
 		e.scrapeErrorsTotal.WithLabelValues("fetch_setup_keys").Inc()
@@ -177,6 +316,15 @@ func (e *SetupKeysExporter) Collect(ch chan<- prometheus.Metric) {
 	}
 
 	e.updateMetrics(setupKeys)
+	e.updateExpiryMetrics(setupKeys, time.Now())
+	e.updateUsageMetrics(setupKeys, time.Now())
+
+	if groupsErr != nil {
+		logrus.WithError(groupsErr).Error("Failed to fetch groups for setup key auto-groups")
+		e.scrapeErrorsTotal.WithLabelValues("fetch_groups").Inc()
+	} else {
+		e.updateAutoGroupMetrics(setupKeys, groups)
+	}
 
 	// Collect all metrics
 	e.setupKeysTotal.Collect(ch)
@@ -189,6 +337,14 @@ func (e *SetupKeysExporter) Collect(ch chan<- prometheus.Metric) {
 	e.setupKeyLastUsed.Collect(ch)
 	e.setupKeyInfo.Collect(ch)
 	e.setupKeyAutoGroups.Collect(ch)
+	e.setupKeyExpiresIn.Collect(ch)
+	e.setupKeyRemaining.Collect(ch)
+	e.setupKeysExpiring.Collect(ch)
+	e.setupKeyAutoGroup.Collect(ch)
+	e.setupKeyDangling.Collect(ch)
+	e.setupKeyUses.Collect(ch)
+	e.setupKeyEnrollRate.Collect(ch)
+	e.setupKeyUsageSpike.Collect(ch)
 	e.scrapeErrorsTotal.Collect(ch)
 	e.scrapeDuration.Collect(ch)
 }
@@ -254,3 +410,145 @@ func (e *SetupKeysExporter) updateMetrics(setupKeys []api.SetupKey) {
 		"revoked_keys": revokedCounts[true],
 	}).Debug("Updated setup key metrics")
 }
+
+// updateExpiryMetrics computes how long usable setup keys remain valid and how
+// many uses they have left. Invalid and revoked keys are skipped since they can no
+// longer enroll peers.
+func (e *SetupKeysExporter) updateExpiryMetrics(setupKeys []api.SetupKey, now time.Time) {
+	expiringCounts := make([]int, len(setupKeyExpiryWindows))
+	usableKeys := 0
+
+	for _, key := range setupKeys {
+		if !key.Valid || key.Revoked {
+			continue
+		}
+		usableKeys++
+		keyLabels := []string{key.Id, key.Name}
+
+		if !key.Expires.IsZero() {
+			expiresIn := key.Expires.Sub(now)
+			if expiresIn < 0 {
+				expiresIn = 0
+			}
+			e.setupKeyExpiresIn.WithLabelValues(keyLabels...).Set(expiresIn.Seconds())
+
+			for i, window := range setupKeyExpiryWindows {
+				if expiresIn <= window.duration {
+					expiringCounts[i]++
+				}
+			}
+		}
+
+		// A usage limit of 0 means the key can be used without limit
+		if key.UsageLimit > 0 {
+			remaining := key.UsageLimit - key.UsedTimes
+			if remaining < 0 {
+				remaining = 0
+			}
+			e.setupKeyRemaining.WithLabelValues(keyLabels...).Set(float64(remaining))
+		}
+	}
+
+	for i, window := range setupKeyExpiryWindows {
+		e.setupKeysExpiring.WithLabelValues(window.label).Set(float64(expiringCounts[i]))
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"usable_keys":     usableKeys,
+		"expiring_in_24h": expiringCounts[0],
+	}).Debug("Updated setup key expiry metrics")
+}
+
+// updateAutoGroupMetrics resolves the auto-groups of each setup key to group names
+// and counts auto-groups referencing groups that no longer exist, which would
+// silently leave newly enrolled peers without their intended access
+func (e *SetupKeysExporter) updateAutoGroupMetrics(setupKeys []api.SetupKey, groups []api.Group) {
+	groupNames := make(map[string]string, len(groups))
+	for _, group := range groups {
+		groupNames[group.Id] = group.Name
+	}
+
+	keysWithDangling := 0
+	for _, key := range setupKeys {
+		dangling := 0
+		for _, groupID := range key.AutoGroups {
+			groupName, ok := groupNames[groupID]
+			if !ok {
+				dangling++
+			}
+			e.setupKeyAutoGroup.WithLabelValues(key.Id, key.Name, groupID, groupName).Set(1)
+		}
+		if dangling > 0 {
+			keysWithDangling++
+		}
+		e.setupKeyDangling.WithLabelValues(key.Id, key.Name).Set(float64(dangling))
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"keys_with_dangling_auto_groups": keysWithDangling,
+	}).Debug("Updated setup key auto-group metrics")
+}
+
+// updateUsageMetrics tracks the use count of each setup key between polls. Increases
+// are added to netbird_setup_key_uses_total and turned into an hourly enrollment
+// rate; a reusable key used more often than the threshold may have leaked.
+func (e *SetupKeysExporter) updateUsageMetrics(setupKeys []api.SetupKey, now time.Time) {
+	e.usageMu.Lock()
+	defer e.usageMu.Unlock()
+
+	present := make(map[string]bool, len(setupKeys))
+	spikes := 0
+
+	for _, key := range setupKeys {
+		present[key.Id] = true
+		keyLabels := []string{key.Id, key.Name}
+
+		usage, ok := e.usage[key.Id]
+		if !ok {
+			usage = &setupKeyUsage{}
+			e.usage[key.Id] = usage
+			// Uses before the exporter started are counted once so the counter matches the API
+			e.setupKeyUses.WithLabelValues(keyLabels...).Add(float64(key.UsedTimes))
+		} else if last := usage.samples[len(usage.samples)-1]; key.UsedTimes > last.usedTimes {
+			e.setupKeyUses.WithLabelValues(keyLabels...).Add(float64(key.UsedTimes - last.usedTimes))
+		}
+
+		usage.samples = append(usage.samples, usageSample{at: now, usedTimes: key.UsedTimes})
+		// Keep the newest sample older than the window as the baseline for the rate
+		expired := 0
+		for expired+1 < len(usage.samples) && now.Sub(usage.samples[expired+1].at) >= setupKeyUsageWindow {
+			expired++
+		}
+		usage.samples = usage.samples[expired:]
+
+		oldest := usage.samples[0]
+		enrollments := key.UsedTimes - oldest.usedTimes
+		if enrollments < 0 {
+			enrollments = 0
+		}
+		// Divide by the full window so a burst right after startup is not extrapolated
+		e.setupKeyEnrollRate.WithLabelValues(keyLabels...).Set(float64(enrollments) / setupKeyUsageWindow.Hours())
+
+		if key.Type == "reusable" {
+			spike := 0.0
+			if enrollments > e.opts.SetupKeyLeakThreshold {
+				spike = 1
+				spikes++
+			}
+			e.setupKeyUsageSpike.WithLabelValues(keyLabels...).Set(spike)
+		}
+	}
+
+	// Forget deleted keys so their counters do not linger
+	for keyID := range e.usage {
+		if !present[keyID] {
+			delete(e.usage, keyID)
+			e.setupKeyUses.DeletePartialMatch(prometheus.Labels{"key_id": keyID})
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"tracked_keys": len(e.usage),
+		"usage_spikes": spikes,
+	}).Debug("Updated setup key usage metrics")
+}
@@ -339,3 +339,155 @@ func TestSetupKeysExporter_MetricsReset(t *testing.T) {
 		}
 	}
 }
+
+func TestSetupKeysExporter_Expiry(t *testing.T) {
+	client := nbclient.New("https://api.netbird.io", "test-token")
+	exporter := NewSetupKeysExporter(client)
+
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	exporter.updateExpiryMetrics([]api.SetupKey{
+		{Id: "key1", Name: "ci", Valid: true, Expires: now.Add(12 * time.Hour), UsageLimit: 10, UsedTimes: 7},
+		{Id: "key2", Name: "fleet", Valid: true, Expires: now.Add(5 * 24 * time.Hour)},
+		{Id: "key3", Name: "later", Valid: true, Expires: now.Add(90 * 24 * time.Hour), UsageLimit: 1, UsedTimes: 1},
+		{Id: "key4", Name: "revoked", Valid: true, Revoked: true, Expires: now.Add(time.Hour)},
+		{Id: "key5", Name: "expired", Valid: false, Expires: now.Add(-time.Hour)},
+	}, now)
+
+	tests := []struct {
+		collector prometheus.Collector
+		name      string
+		labels    map[string]string
+		expected  float64
+	}{
+		{exporter.setupKeyExpiresIn, "netbird_setup_key_expires_in_seconds", map[string]string{"key_id": "key1"}, 12 * 3600},
+		{exporter.setupKeyRemaining, "netbird_setup_key_remaining_uses", map[string]string{"key_id": "key1"}, 3},
+		{exporter.setupKeyRemaining, "netbird_setup_key_remaining_uses", map[string]string{"key_id": "key3"}, 0},
+		{exporter.setupKeysExpiring, "netbird_setup_keys_expiring", map[string]string{"within": "24h"}, 1},
+		{exporter.setupKeysExpiring, "netbird_setup_keys_expiring", map[string]string{"within": "7d"}, 2},
+		{exporter.setupKeysExpiring, "netbird_setup_keys_expiring", map[string]string{"within": "30d"}, 2},
+	}
+	for _, tt := range tests {
+		value, found := gatherMetricValue(t, tt.collector, tt.name, tt.labels)
+		if !found || value != tt.expected {
+			t.Errorf("Expected %s%v to be %f, got %f (found=%v)", tt.name, tt.labels, tt.expected, value, found)
+		}
+	}
+
+	// Unlimited, revoked and invalid keys have no remaining uses or expiry series
+	for _, keyID := range []string{"key2", "key4", "key5"} {
+		if _, found := gatherMetricValue(t, exporter.setupKeyRemaining, "netbird_setup_key_remaining_uses", map[string]string{"key_id": keyID}); found {
+			t.Errorf("Expected no remaining uses series for %s", keyID)
+		}
+	}
+	if _, found := gatherMetricValue(t, exporter.setupKeyExpiresIn, "netbird_setup_key_expires_in_seconds", map[string]string{"key_id": "key4"}); found {
+		t.Error("Expected no expiry series for a revoked key")
+	}
+}
+
+func TestSetupKeysExporter_AutoGroups(t *testing.T) {
+	client := nbclient.New("https://api.netbird.io", "test-token")
+	exporter := NewSetupKeysExporter(client)
+
+	exporter.updateAutoGroupMetrics([]api.SetupKey{
+		{Id: "key1", Name: "servers", AutoGroups: []string{"group1", "deleted"}},
+		{Id: "key2", Name: "laptops", AutoGroups: []string{"group2"}},
+	}, []api.Group{
+		{Id: "group1", Name: "Servers"},
+		{Id: "group2", Name: "Laptops"},
+	})
+
+	if _, found := gatherMetricValue(t, exporter.setupKeyAutoGroup, "netbird_setup_key_auto_group", map[string]string{"key_id": "key1", "group_id": "group1", "group_name": "Servers"}); !found {
+		t.Error("Expected auto-group to be resolved to its name")
+	}
+	if _, found := gatherMetricValue(t, exporter.setupKeyAutoGroup, "netbird_setup_key_auto_group", map[string]string{"key_id": "key1", "group_id": "deleted", "group_name": ""}); !found {
+		t.Error("Expected dangling auto-group to be exported with an empty name")
+	}
+
+	tests := []struct {
+		keyID    string
+		expected float64
+	}{
+		{"key1", 1},
+		{"key2", 0},
+	}
+	for _, tt := range tests {
+		value, found := gatherMetricValue(t, exporter.setupKeyDangling, "netbird_setup_key_dangling_auto_groups", map[string]string{"key_id": tt.keyID})
+		if !found || value != tt.expected {
+			t.Errorf("Expected %f dangling auto-groups for %s, got %f (found=%v)", tt.expected, tt.keyID, value, found)
+		}
+	}
+}
+
+func TestSetupKeysExporter_Usage(t *testing.T) {
+	client := nbclient.New("https://api.netbird.io", "test-token")
+	opts := DefaultOptions()
+	opts.SetupKeyLeakThreshold = 5
+	exporter := NewSetupKeysExporterWithOptions(client, opts)
+
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	exporter.updateUsageMetrics([]api.SetupKey{
+		{Id: "key1", Name: "fleet", Type: "reusable", UsedTimes: 100},
+		{Id: "key2", Name: "laptop", Type: "one-off", UsedTimes: 0},
+	}, start)
+	exporter.updateUsageMetrics([]api.SetupKey{
+		{Id: "key1", Name: "fleet", Type: "reusable", UsedTimes: 120},
+		{Id: "key2", Name: "laptop", Type: "one-off", UsedTimes: 1},
+	}, start.Add(30*time.Minute))
+
+	tests := []struct {
+		collector prometheus.Collector
+		name      string
+		keyID     string
+		expected  float64
+	}{
+		{exporter.setupKeyUses, "netbird_setup_key_uses_total", "key1", 120},
+		{exporter.setupKeyUses, "netbird_setup_key_uses_total", "key2", 1},
+		{exporter.setupKeyEnrollRate, "netbird_setup_key_enrollments_rate", "key1", 20},
+		{exporter.setupKeyEnrollRate, "netbird_setup_key_enrollments_rate", "key2", 1},
+		{exporter.setupKeyUsageSpike, "netbird_setup_key_usage_spike", "key1", 1},
+	}
+	for _, tt := range tests {
+		value, found := gatherMetricValue(t, tt.collector, tt.name, map[string]string{"key_id": tt.keyID})
+		if !found || value != tt.expected {
+			t.Errorf("Expected %s for %s to be %f, got %f (found=%v)", tt.name, tt.keyID, tt.expected, value, found)
+		}
+	}
+
+	// One-off keys are never flagged
+	if _, found := gatherMetricValue(t, exporter.setupKeyUsageSpike, "netbird_setup_key_usage_spike", map[string]string{"key_id": "key2"}); found {
+		t.Error("Expected no usage spike series for a one-off key")
+	}
+
+	// Once the burst leaves the window the key is no longer flagged, and deleted keys are forgotten
+	exporter.setupKeyUsageSpike.Reset()
+	exporter.updateUsageMetrics([]api.SetupKey{
+		{Id: "key1", Name: "fleet", Type: "reusable", UsedTimes: 121},
+	}, start.Add(2*time.Hour))
+
+	spike, _ := gatherMetricValue(t, exporter.setupKeyUsageSpike, "netbird_setup_key_usage_spike", map[string]string{"key_id": "key1"})
+	if spike != 0 {
+		t.Errorf("Expected usage spike to clear, got %f", spike)
+	}
+	rate, _ := gatherMetricValue(t, exporter.setupKeyEnrollRate, "netbird_setup_key_enrollments_rate", map[string]string{"key_id": "key1"})
+	if rate != 1 {
+		t.Errorf("Expected 1 enrollment per hour, got %f", rate)
+	}
+	if _, found := gatherMetricValue(t, exporter.setupKeyUses, "netbird_setup_key_uses_total", map[string]string{"key_id": "key2"}); found {
+		t.Error("Expected deleted key counter to be removed")
+	}
+}
+
+func TestSetupKeysExporter_UsageRateAfterStartup(t *testing.T) {
+	client := nbclient.New("https://api.netbird.io", "test-token")
+	exporter := NewSetupKeysExporter(client)
+
+	// A single enrollment shortly after startup is one per hour, not 240
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	exporter.updateUsageMetrics([]api.SetupKey{{Id: "key1", Name: "fleet", Type: "reusable", UsedTimes: 10}}, start)
+	exporter.updateUsageMetrics([]api.SetupKey{{Id: "key1", Name: "fleet", Type: "reusable", UsedTimes: 11}}, start.Add(15*time.Second))
+
+	rate, found := gatherMetricValue(t, exporter.setupKeyEnrollRate, "netbird_setup_key_enrollments_rate", map[string]string{"key_id": "key1"})
+	if !found || rate != 1 {
+		t.Errorf("Expected 1 enrollment per hour, got %f (found=%v)", rate, found)
+	}
+}
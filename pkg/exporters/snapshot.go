@@ -0,0 +1,122 @@
+package exporters
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	nbclient "github.com/netbirdio/netbird/shared/management/client/rest"
+	"github.com/netbirdio/netbird/shared/management/http/api"
+)
+
+// lazyFetch requests a NetBird API object at most once and shares the result
+// with every caller, including concurrent ones
+type lazyFetch[T any] struct {
+	once  sync.Once
+	fetch func(ctx context.Context) (T, error)
+	value T
+	err   error
+}
+
+func newLazyFetch[T any](fetch func(ctx context.Context) (T, error)) *lazyFetch[T] {
+	return &lazyFetch[T]{fetch: fetch}
+}
+
+// load performs the request if it has not been made yet. Requests often run in
+// goroutines, so a panic is returned as the request error instead of crashing.
+func (l *lazyFetch[T]) load(ctx context.Context) {
+	l.once.Do(func() {
+		defer func() {
+			if r := recover(); r != nil {
+				l.err = fmt.Errorf("panic during request: %v", r)
+			}
+		}()
+		l.value, l.err = l.fetch(ctx)
+	})
+}
+
+// get returns the fetched object, performing the request on first use
+func (l *lazyFetch[T]) get(ctx context.Context) (T, error) {
+	l.load(ctx)
+	return l.value, l.err
+}
+
+// apiSnapshot holds the NetBird API objects used during a single scrape. Each
+// object is requested once and shared by all sub-exporters reading it, so that
+// adding metrics to one exporter does not multiply the load on the API.
+type apiSnapshot struct {
+	peers            *lazyFetch[[]api.Peer]
+	groups           *lazyFetch[[]api.Group]
+	users            *lazyFetch[[]api.User]
+	policies         *lazyFetch[[]api.Policy]
+	routes           *lazyFetch[[]api.Route]
+	nameserverGroups *lazyFetch[[]api.NameserverGroup]
+	setupKeys        *lazyFetch[[]api.SetupKey]
+	networkRouters   *lazyFetch[[]api.NetworkRouter]
+	dnsSettings      *lazyFetch[*api.DNSSettings]
+	accounts         *lazyFetch[[]api.Account]
+}
+
+// newAPISnapshot creates an empty snapshot; objects are fetched on first use
+func newAPISnapshot(client *nbclient.Client) *apiSnapshot {
+	return &apiSnapshot{
+		peers: newLazyFetch(func(ctx context.Context) ([]api.Peer, error) {
+			return client.Peers.List(ctx)
+		}),
+		groups: newLazyFetch(func(ctx context.Context) ([]api.Group, error) {
+			return client.Groups.List(ctx)
+		}),
+		users: newLazyFetch(func(ctx context.Context) ([]api.User, error) {
+			return client.Users.List(ctx)
+		}),
+		policies: newLazyFetch(func(ctx context.Context) ([]api.Policy, error) {
+			return client.Policies.List(ctx)
+		}),
+		routes: newLazyFetch(func(ctx context.Context) ([]api.Route, error) {
+			return client.Routes.List(ctx)
+		}),
+		nameserverGroups: newLazyFetch(func(ctx context.Context) ([]api.NameserverGroup, error) {
+			return client.DNS.ListNameserverGroups(ctx)
+		}),
+		setupKeys: newLazyFetch(func(ctx context.Context) ([]api.SetupKey, error) {
+			return client.SetupKeys.List(ctx)
+		}),
+		networkRouters: newLazyFetch(func(ctx context.Context) ([]api.NetworkRouter, error) {
+			return client.Networks.ListAllRouters(ctx)
+		}),
+		dnsSettings: newLazyFetch(func(ctx context.Context) (*api.DNSSettings, error) {
+			return client.DNS.GetSettings(ctx)
+		}),
+		accounts: newLazyFetch(func(ctx context.Context) ([]api.Account, error) {
+			return client.Accounts.List(ctx)
+		}),
+	}
+}
+
+// prefetch requests every object of the snapshot concurrently and waits for
+// all of them, so that sub-exporters do not wait for API round trips one after
+// another
+func (s *apiSnapshot) prefetch(ctx context.Context) {
+	loads := []func(context.Context){
+		s.peers.load,
+		s.groups.load,
+		s.users.load,
+		s.policies.load,
+		s.routes.load,
+		s.nameserverGroups.load,
+		s.setupKeys.load,
+		s.networkRouters.load,
+		s.dnsSettings.load,
+		s.accounts.load,
+	}
+
+	var wg sync.WaitGroup
+	for _, load := range loads {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			load(ctx)
+		}()
+	}
+	wg.Wait()
+}
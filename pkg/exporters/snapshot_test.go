@@ -0,0 +1,93 @@
+package exporters
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestLazyFetch_FetchesOnce(t *testing.T) {
+	var calls atomic.Int32
+	fetch := newLazyFetch(func(ctx context.Context) (int, error) {
+		calls.Add(1)
+		return 42, errors.New("partial")
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := fetch.get(context.Background())
+			if value != 42 || err == nil {
+				t.Errorf("Expected shared value and error, got %d, %v", value, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("Expected a single request, got %d", calls.Load())
+	}
+}
+
+func TestLazyFetch_PanicReturnedAsError(t *testing.T) {
+	fetch := newLazyFetch(func(ctx context.Context) (int, error) {
+		panic("boom")
+	})
+
+	done := make(chan error)
+	go func() {
+		_, err := fetch.get(context.Background())
+		done <- err
+	}()
+
+	if err := <-done; err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Expected the panic to be returned as an error, got %v", err)
+	}
+}
+
+func TestNetBirdExporter_Collect_SharesAPIObjects(t *testing.T) {
+	var mu sync.Mutex
+	requests := make(map[string]int)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/api/dns/settings" {
+			_, _ = w.Write([]byte(`{"disabled_management_groups":[]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	exporter := NewNetBirdExporter(server.URL, "test-token")
+
+	ch := make(chan prometheus.Metric, 1000)
+	go func() {
+		exporter.Collect(ch)
+		close(ch)
+	}()
+	for range ch {
+		// Drain channel
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, path := range []string{"/api/peers", "/api/groups", "/api/users", "/api/policies", "/api/routes", "/api/setup-keys", "/api/dns/nameservers", "/api/dns/settings", "/api/networks/routers", "/api/accounts"} {
+		if requests[path] != 1 {
+			t.Errorf("Expected %s to be requested once per scrape, got %d", path, requests[path])
+		}
+	}
+}
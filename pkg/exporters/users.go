@@ -2,6 +2,8 @@ package exporters
 
 import (
 	"context"
+	"strconv"
+	"sync"
 	"time"
 
 	nbclient "github.com/netbirdio/netbird/shared/management/client/rest"
@@ -13,26 +15,93 @@ import (
 // UsersExporter handles users-specific metrics collection
 type UsersExporter struct {
 	client *nbclient.Client
+	opts   Options
 
 	// Prometheus metrics for users
-	usersTotal           *prometheus.GaugeVec
-	usersByRole          *prometheus.GaugeVec
-	usersByStatus        *prometheus.GaugeVec
-	usersServiceUsers    *prometheus.GaugeVec
-	usersBlocked         *prometheus.GaugeVec
-	usersByIssued        *prometheus.GaugeVec
-	usersLastLogin       *prometheus.GaugeVec
-	usersAutoGroupsCount *prometheus.GaugeVec
-	usersRestricted      *prometheus.GaugeVec
-	usersPermissions     *prometheus.GaugeVec
-	scrapeErrorsTotal    *prometheus.CounterVec
-	scrapeDuration       *prometheus.HistogramVec
+	usersTotal            *prometheus.GaugeVec
+	usersByRole           *prometheus.GaugeVec
+	usersByStatus         *prometheus.GaugeVec
+	usersServiceUsers     *prometheus.GaugeVec
+	usersBlocked          *prometheus.GaugeVec
+	usersByIssued         *prometheus.GaugeVec
+	usersLastLogin        *prometheus.GaugeVec
+	usersAutoGroupsCount  *prometheus.GaugeVec
+	usersRestricted       *prometheus.GaugeVec
+	usersPermissions      *prometheus.GaugeVec
+	usersWithPermission   *prometheus.GaugeVec
+	userPeers             *prometheus.GaugeVec
+	usersWithoutPeers     *prometheus.GaugeVec
+	usersPeersAbove       *prometheus.GaugeVec
+	usersBlockedWithPeers *prometheus.GaugeVec
+	peersUnknownUser      *prometheus.GaugeVec
+	userTokenExpiration   *prometheus.GaugeVec
+	usersInactive         *prometheus.GaugeVec
+	usersNeverLoggedIn    *prometheus.GaugeVec
+	usersActiveByRole     *prometheus.GaugeVec
+	usersPending          *prometheus.GaugeVec
+	usersPendingOldestAge *prometheus.GaugeVec
+	privilegedChanges     *prometheus.CounterVec
+	privilegedChangeInfo  *prometheus.GaugeVec
+	userTokenLastUsed     *prometheus.GaugeVec
+	scrapeErrorsTotal     *prometheus.CounterVec
+	scrapeDuration        *prometheus.HistogramVec
+
+	// Role observed for each user during the previous poll, nil before the first poll
+	stateMu         sync.Mutex
+	previousRoles   map[string]string
+	privilegedSince map[string]privilegedChange
+	// Time each user was first observed pending, per kind of pending state
+	pendingSince map[userPendingKey]time.Time
+	// ID of the user owning the exporter's token, resolved on first use
+	currentUserID string
 }
 
+// permissionKey identifies an action of a permission module
+type permissionKey struct {
+	module     string
+	permission string
+}
+
+// userPendingKey identifies a user waiting in one kind of pending state
+type userPendingKey struct {
+	kind   string
+	userID string
+}
+
+// Kinds of pending users
+const (
+	userPendingInvited  = "invited"
+	userPendingApproval = "approval"
+)
+
+// privilegedChange records a user gaining a privileged role
+type privilegedChange struct {
+	previousRole string
+	role         string
+}
+
+// privilegedRoles are the roles whose grants are tracked between polls
+var privilegedRoles = map[string]bool{
+	"owner": true,
+	"admin": true,
+}
+
+// reportedRoles are always exported by netbird_users_active_by_role, even when zero
+var reportedRoles = []string{"owner", "admin", "user"}
+
 // NewUsersExporter creates a new users exporter
 func NewUsersExporter(client *nbclient.Client) *UsersExporter {
+	return NewUsersExporterWithOptions(client, DefaultOptions())
+}
+
+// NewUsersExporterWithOptions creates a new users exporter using the given options
+func NewUsersExporterWithOptions(client *nbclient.Client, opts Options) *UsersExporter {
 	return &UsersExporter{
 		client: client,
+		opts:   opts,
+
+		privilegedSince: make(map[string]privilegedChange),
+		pendingSince:    make(map[userPendingKey]time.Time),
 
 		usersTotal: prometheus.NewGaugeVec(
 			prometheus.GaugeOpts{
@@ -114,6 +183,126 @@ func NewUsersExporter(client *nbclient.Client) *UsersExporter {
 			[]string{"user_id", "user_email", "module", "permission", "value"},
 		),
 
+		usersWithPermission: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_users_with_permission",
+				Help: "Number of NetBird users granted each permission by module and action",
+			},
+			[]string{"module", "permission"},
+		),
+
+		userPeers: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_user_peers",
+				Help: "Number of peers enrolled by each NetBird user by connection status",
+			},
+			[]string{"user_id", "user_email", "connected"},
+		),
+
+		usersWithoutPeers: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_users_without_peers",
+				Help: "Number of NetBird users (excluding service users) that have no peers",
+			},
+			[]string{},
+		),
+
+		usersPeersAbove: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_users_peers_above_threshold",
+				Help: "Number of NetBird users owning more peers than the configured threshold",
+			},
+			[]string{"threshold"},
+		),
+
+		usersBlockedWithPeers: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_users_blocked_with_peers",
+				Help: "Number of blocked NetBird users that still own peers",
+			},
+			[]string{},
+		),
+
+		peersUnknownUser: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_peers_unknown_user",
+				Help: "Number of NetBird peers enrolled by a user that no longer exists",
+			},
+			[]string{},
+		),
+
+		usersInactive: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_users_inactive",
+				Help: "Number of NetBird users, excluding service users, whose last login is older than each period",
+			},
+			[]string{"period"},
+		),
+
+		usersNeverLoggedIn: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_users_never_logged_in",
+				Help: "Number of NetBird users, excluding service users, that have never logged in",
+			},
+			[]string{},
+		),
+
+		usersActiveByRole: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_users_active_by_role",
+				Help: "Number of NetBird users by role, excluding blocked and service users",
+			},
+			[]string{"role"},
+		),
+
+		privilegedChanges: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "netbird_users_privileged_changes_total",
+				Help: "Total number of users observed gaining an admin or owner role between polls",
+			},
+			[]string{"role"},
+		),
+
+		privilegedChangeInfo: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_user_privileged_change_info",
+				Help: "Users that gained an admin or owner role while the exporter was running (always 1)",
+			},
+			[]string{"user_id", "previous_role", "role"},
+		),
+
+		usersPending: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_users_pending",
+				Help: "Number of NetBird users with an unaccepted invite or pending approval",
+			},
+			[]string{"kind"},
+		),
+
+		usersPendingOldestAge: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_users_pending_oldest_age_seconds",
+				Help: "Time the longest-pending NetBird user has been pending, as observed by the exporter",
+			},
+			[]string{"kind"},
+		),
+
+		userTokenExpiration: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_user_token_expiration_timestamp",
+				Help: "Expiration time of each personal access token as Unix timestamp",
+			},
+			[]string{"user_id", "token_id", "token_name"},
+		),
+
+		userTokenLastUsed: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "netbird_user_token_last_used_timestamp",
+				Help: "Last time each personal access token was used as Unix timestamp",
+			},
+			[]string{"user_id", "token_id", "token_name"},
+		),
+
 		scrapeErrorsTotal: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "netbird_users_scrape_errors_total",
@@ -144,12 +333,32 @@ func (e *UsersExporter) Describe(ch chan<- *prometheus.Desc) {
 	e.usersAutoGroupsCount.Describe(ch)
 	e.usersRestricted.Describe(ch)
 	e.usersPermissions.Describe(ch)
+	e.usersWithPermission.Describe(ch)
+	e.userPeers.Describe(ch)
+	e.usersWithoutPeers.Describe(ch)
+	e.usersPeersAbove.Describe(ch)
+	e.usersBlockedWithPeers.Describe(ch)
+	e.peersUnknownUser.Describe(ch)
+	e.usersInactive.Describe(ch)
+	e.usersNeverLoggedIn.Describe(ch)
+	e.usersActiveByRole.Describe(ch)
+	e.usersPending.Describe(ch)
+	e.usersPendingOldestAge.Describe(ch)
+	e.privilegedChanges.Describe(ch)
+	e.privilegedChangeInfo.Describe(ch)
+	e.userTokenExpiration.Describe(ch)
+	e.userTokenLastUsed.Describe(ch)
 	e.scrapeErrorsTotal.Describe(ch)
 	e.scrapeDuration.Describe(ch)
 }
 
 // Collect implements prometheus.Collector
 func (e *UsersExporter) Collect(ch chan<- prometheus.Metric) {
+	e.collect(ch, newAPISnapshot(e.client))
+}
+
+// collect updates and collects the user metrics from the objects of one scrape
+func (e *UsersExporter) collect(ch chan<- prometheus.Metric, snapshot *apiSnapshot) {
 	timer := prometheus.NewTimer(e.scrapeDuration.WithLabelValues())
 	defer timer.ObserveDuration()
 
@@ -164,11 +373,25 @@ func (e *UsersExporter) Collect(ch chan<- prometheus.Metric) {
 	e.usersAutoGroupsCount.Reset()
 	e.usersRestricted.Reset()
 	e.usersPermissions.Reset()
+	e.usersWithPermission.Reset()
+	e.userPeers.Reset()
+	e.usersWithoutPeers.Reset()
+	e.usersPeersAbove.Reset()
+	e.usersBlockedWithPeers.Reset()
+	e.peersUnknownUser.Reset()
+	e.usersInactive.Reset()
+	e.usersNeverLoggedIn.Reset()
+	e.usersActiveByRole.Reset()
+	e.usersPending.Reset()
+	e.usersPendingOldestAge.Reset()
+	e.privilegedChangeInfo.Reset()
+	e.userTokenExpiration.Reset()
+	e.userTokenLastUsed.Reset()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	users, err := e.client.Users.List(ctx)
+	users, err := snapshot.users.get(ctx)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to fetch users")
 		e.scrapeErrorsTotal.WithLabelValues("fetch_users").Inc()
@@ -176,6 +399,23 @@ func (e *UsersExporter) Collect(ch chan<- prometheus.Metric) {
 	}
 
 	e.updateMetrics(users)
+	e.updateActivityMetrics(users, time.Now())
+	e.updateRoleMetrics(users)
+	e.updatePendingMetrics(users, time.Now())
+
+	peers, err := snapshot.peers.get(ctx)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch peers for user ownership metrics")
+		e.scrapeErrorsTotal.WithLabelValues("fetch_peers").Inc()
+	} else {
+		e.updatePeerOwnershipMetrics(users, peers)
+	}
+
+	if e.opts.UserTokens {
+		tokensCtx, tokensCancel := context.WithTimeout(context.Background(), userTokensTimeout)
+		e.updateTokenMetrics(users, e.fetchUserTokens(tokensCtx, e.tokenUsers(tokensCtx, users)))
+		tokensCancel()
+	}
 
 	// Collect all metrics
 	e.usersTotal.Collect(ch)
@@ -188,6 +428,21 @@ func (e *UsersExporter) Collect(ch chan<- prometheus.Metric) {
 	e.usersAutoGroupsCount.Collect(ch)
 	e.usersRestricted.Collect(ch)
 	e.usersPermissions.Collect(ch)
+	e.usersWithPermission.Collect(ch)
+	e.userPeers.Collect(ch)
+	e.usersWithoutPeers.Collect(ch)
+	e.usersPeersAbove.Collect(ch)
+	e.usersBlockedWithPeers.Collect(ch)
+	e.peersUnknownUser.Collect(ch)
+	e.usersInactive.Collect(ch)
+	e.usersNeverLoggedIn.Collect(ch)
+	e.usersActiveByRole.Collect(ch)
+	e.usersPending.Collect(ch)
+	e.usersPendingOldestAge.Collect(ch)
+	e.privilegedChanges.Collect(ch)
+	e.privilegedChangeInfo.Collect(ch)
+	e.userTokenExpiration.Collect(ch)
+	e.userTokenLastUsed.Collect(ch)
 	e.scrapeErrorsTotal.Collect(ch)
 	e.scrapeDuration.Collect(ch)
 }
@@ -207,6 +462,7 @@ func (e *UsersExporter) updateMetrics(users []api.User) {
 	restrictedCount := 0
 	unrestrictedCount := 0
 	totalPermissionsCount := 0
+	permissionCounts := make(map[permissionKey]int)
 
 	for _, user := range users {
 		// Role distribution
@@ -269,7 +525,19 @@ func (e *UsersExporter) updateMetrics(users []api.User) {
 		}
 		// User permissions per module and action
 		for module, permissions := range user.Permissions.Modules {
+			perUser := e.exportUserPermissions(module)
 			for permission, value := range permissions {
+				key := permissionKey{module: module, permission: permission}
+				if _, ok := permissionCounts[key]; !ok {
+					permissionCounts[key] = 0
+				}
+				if value {
+					permissionCounts[key]++
+				}
+				if !perUser {
+					continue
+				}
+
 				valueStr := "false"
 				if value {
 					valueStr = "true"
@@ -310,6 +578,11 @@ func (e *UsersExporter) updateMetrics(users []api.User) {
 	e.usersRestricted.WithLabelValues("true").Set(float64(restrictedCount))
 	e.usersRestricted.WithLabelValues("false").Set(float64(unrestrictedCount))
 
+	// Users granted each permission
+	for key, count := range permissionCounts {
+		e.usersWithPermission.WithLabelValues(key.module, key.permission).Set(float64(count))
+	}
+
 	logrus.WithFields(logrus.Fields{
 		"total_users":             totalUsers,
 		"service_users":           serviceUserCount,
@@ -322,5 +595,337 @@ func (e *UsersExporter) updateMetrics(users []api.User) {
 		"status_distributions":    statusCounts,
 		"issued_distributions":    issuedCounts,
 		"total_permissions_count": totalPermissionsCount,
+		"distinct_permissions":    len(permissionCounts),
 	}).Debug("Updated user metrics")
 }
+
+// updatePeerOwnershipMetrics relates peers to the users that enrolled them
+func (e *UsersExporter) updatePeerOwnershipMetrics(users []api.User, peers []api.Peer) {
+	connectedByUser := make(map[string]int)
+	disconnectedByUser := make(map[string]int)
+	knownUsers := make(map[string]bool, len(users))
+	unknownUserPeers := 0
+
+	for _, user := range users {
+		knownUsers[user.Id] = true
+	}
+
+	for _, peer := range peers {
+		// Peers enrolled with a setup key have no owning user
+		if peer.UserId == "" {
+			continue
+		}
+		if !knownUsers[peer.UserId] {
+			unknownUserPeers++
+			continue
+		}
+		if peer.Connected {
+			connectedByUser[peer.UserId]++
+		} else {
+			disconnectedByUser[peer.UserId]++
+		}
+	}
+
+	withoutPeers := 0
+	aboveThreshold := 0
+	blockedWithPeers := 0
+
+	for _, user := range users {
+		owned := connectedByUser[user.Id] + disconnectedByUser[user.Id]
+		isServiceUser := user.IsServiceUser != nil && *user.IsServiceUser
+
+		e.userPeers.WithLabelValues(user.Id, user.Email, "true").Set(float64(connectedByUser[user.Id]))
+		e.userPeers.WithLabelValues(user.Id, user.Email, "false").Set(float64(disconnectedByUser[user.Id]))
+
+		if owned == 0 && !isServiceUser {
+			withoutPeers++
+		}
+		if owned > e.opts.UserPeersThreshold {
+			aboveThreshold++
+		}
+		if owned > 0 && user.IsBlocked {
+			blockedWithPeers++
+		}
+	}
+
+	e.usersWithoutPeers.WithLabelValues().Set(float64(withoutPeers))
+	e.usersPeersAbove.WithLabelValues(strconv.Itoa(e.opts.UserPeersThreshold)).Set(float64(aboveThreshold))
+	e.usersBlockedWithPeers.WithLabelValues().Set(float64(blockedWithPeers))
+	e.peersUnknownUser.WithLabelValues().Set(float64(unknownUserPeers))
+
+	logrus.WithFields(logrus.Fields{
+		"users_without_peers":      withoutPeers,
+		"users_above_threshold":    aboveThreshold,
+		"blocked_users_with_peers": blockedWithPeers,
+		"peers_with_unknown_user":  unknownUserPeers,
+	}).Debug("Updated user peer ownership metrics")
+}
+
+// exportUserPermissions reports whether the per-user permission matrix is exported
+// for a module, either because the full matrix is enabled or the module is allowlisted
+func (e *UsersExporter) exportUserPermissions(module string) bool {
+	if e.opts.UserPermissionsPerUser {
+		return true
+	}
+	for _, allowed := range e.opts.UserPermissionsModules {
+		if allowed == module {
+			return true
+		}
+	}
+	return false
+}
+
+// updateActivityMetrics counts users whose last login is older than each configured
+// period and users that never logged in. Service users do not log in and are skipped;
+// users that never logged in are only reported by netbird_users_never_logged_in.
+func (e *UsersExporter) updateActivityMetrics(users []api.User, now time.Time) {
+	inactiveCounts := make([]int, len(e.opts.UserInactivePeriods))
+	neverLoggedIn := 0
+
+	for _, user := range users {
+		if user.IsServiceUser != nil && *user.IsServiceUser {
+			continue
+		}
+		if user.LastLogin == nil || user.LastLogin.IsZero() {
+			neverLoggedIn++
+			continue
+		}
+
+		idle := now.Sub(*user.LastLogin)
+		for i, period := range e.opts.UserInactivePeriods {
+			if idle > period.Duration {
+				inactiveCounts[i]++
+			}
+		}
+	}
+
+	for i, period := range e.opts.UserInactivePeriods {
+		e.usersInactive.WithLabelValues(period.Label).Set(float64(inactiveCounts[i]))
+	}
+	e.usersNeverLoggedIn.WithLabelValues().Set(float64(neverLoggedIn))
+
+	logrus.WithFields(logrus.Fields{
+		"inactive_periods": len(e.opts.UserInactivePeriods),
+		"never_logged_in":  neverLoggedIn,
+	}).Debug("Updated user activity metrics")
+}
+
+// updateRoleMetrics counts active users per role and detects users gaining a
+// privileged role since the previous poll. Users already privileged on the first
+// poll are taken as the baseline and not counted as changes.
+func (e *UsersExporter) updateRoleMetrics(users []api.User) {
+	activeRoles := make(map[string]int, len(reportedRoles))
+	for _, role := range reportedRoles {
+		activeRoles[role] = 0
+	}
+
+	e.stateMu.Lock()
+	defer e.stateMu.Unlock()
+
+	baseline := e.previousRoles == nil
+	roles := make(map[string]string, len(users))
+	changes := 0
+
+	for _, user := range users {
+		roles[user.Id] = user.Role
+
+		if !user.IsBlocked && (user.IsServiceUser == nil || !*user.IsServiceUser) {
+			role := user.Role
+			if role == "" {
+				role = "unknown"
+			}
+			activeRoles[role]++
+		}
+
+		if !privilegedRoles[user.Role] {
+			delete(e.privilegedSince, user.Id)
+			continue
+		}
+		if baseline {
+			continue
+		}
+
+		previousRole, known := e.previousRoles[user.Id]
+		if known && previousRole == user.Role {
+			continue
+		}
+		if !known {
+			previousRole = "none"
+		}
+		e.privilegedChanges.WithLabelValues(user.Role).Inc()
+		e.privilegedSince[user.Id] = privilegedChange{previousRole: previousRole, role: user.Role}
+		changes++
+	}
+
+	// Forget users that no longer exist
+	for userID := range e.privilegedSince {
+		if _, ok := roles[userID]; !ok {
+			delete(e.privilegedSince, userID)
+		}
+	}
+	e.previousRoles = roles
+
+	for role, count := range activeRoles {
+		e.usersActiveByRole.WithLabelValues(role).Set(float64(count))
+	}
+	for userID, change := range e.privilegedSince {
+		e.privilegedChangeInfo.WithLabelValues(userID, change.previousRole, change.role).Set(1)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"active_roles":       activeRoles,
+		"privileged_changes": changes,
+	}).Debug("Updated user role metrics")
+}
+
+// updatePendingMetrics counts invited and approval-pending users and tracks how
+// long each has been pending. The API does not report when a user became pending,
+// so ages start when the exporter first observes the user.
+func (e *UsersExporter) updatePendingMetrics(users []api.User, now time.Time) {
+	counts := map[string]int{userPendingInvited: 0, userPendingApproval: 0}
+	oldest := map[string]time.Duration{userPendingInvited: 0, userPendingApproval: 0}
+
+	e.stateMu.Lock()
+	defer e.stateMu.Unlock()
+
+	pending := make(map[userPendingKey]bool)
+	for _, user := range users {
+		if user.Status == api.UserStatusInvited {
+			pending[userPendingKey{kind: userPendingInvited, userID: user.Id}] = true
+		}
+		if user.PendingApproval {
+			pending[userPendingKey{kind: userPendingApproval, userID: user.Id}] = true
+		}
+	}
+
+	// Users accepting, approved or deleted leave the pending state
+	for key := range e.pendingSince {
+		if !pending[key] {
+			delete(e.pendingSince, key)
+		}
+	}
+
+	for key := range pending {
+		since, ok := e.pendingSince[key]
+		if !ok {
+			since = now
+			e.pendingSince[key] = since
+		}
+		counts[key.kind]++
+		if age := now.Sub(since); age > oldest[key.kind] {
+			oldest[key.kind] = age
+		}
+	}
+
+	for kind, count := range counts {
+		e.usersPending.WithLabelValues(kind).Set(float64(count))
+		e.usersPendingOldestAge.WithLabelValues(kind).Set(oldest[kind].Seconds())
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"pending_users":      counts,
+		"oldest_pending_age": oldest,
+	}).Debug("Updated pending user metrics")
+}
+
+const (
+	// userTokensConcurrency limits the number of token requests in flight at once
+	userTokensConcurrency = 4
+	// userTokensTimeout bounds all token requests of a scrape, separately from
+	// the other user requests
+	userTokensTimeout = 30 * time.Second
+)
+
+// tokenUsers returns the users whose tokens the exporter may list. NetBird only
+// lets a user list their own tokens, and admins those of service users, so
+// requesting any other user's tokens always fails.
+func (e *UsersExporter) tokenUsers(ctx context.Context, users []api.User) []api.User {
+	currentUserID := e.resolveCurrentUserID(ctx)
+
+	var result []api.User
+	for _, user := range users {
+		if (user.IsServiceUser != nil && *user.IsServiceUser) || user.Id == currentUserID {
+			result = append(result, user)
+		}
+	}
+	return result
+}
+
+// resolveCurrentUserID returns the ID of the user owning the exporter's token.
+// It is requested once; a failed lookup is retried on the next scrape.
+func (e *UsersExporter) resolveCurrentUserID(ctx context.Context) string {
+	e.stateMu.Lock()
+	currentUserID := e.currentUserID
+	e.stateMu.Unlock()
+	if currentUserID != "" {
+		return currentUserID
+	}
+
+	user, err := e.client.Users.Current(ctx)
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to fetch current user, listing service user tokens only")
+		e.scrapeErrorsTotal.WithLabelValues("fetch_current_user").Inc()
+		return ""
+	}
+
+	e.stateMu.Lock()
+	e.currentUserID = user.Id
+	e.stateMu.Unlock()
+	return user.Id
+}
+
+// fetchUserTokens lists the personal access tokens of each user. Users whose
+// tokens cannot be fetched are counted as scrape errors and left out.
+func (e *UsersExporter) fetchUserTokens(ctx context.Context, users []api.User) map[string][]api.PersonalAccessToken {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	tokens := make(map[string][]api.PersonalAccessToken, len(users))
+	semaphore := make(chan struct{}, userTokensConcurrency)
+
+	for _, user := range users {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(userID string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			userTokens, err := e.client.Tokens.List(ctx, userID)
+			if err != nil {
+				logrus.WithError(err).WithField("user_id", userID).Error("Failed to fetch user tokens")
+				e.scrapeErrorsTotal.WithLabelValues("fetch_tokens").Inc()
+				return
+			}
+
+			mu.Lock()
+			tokens[userID] = userTokens
+			mu.Unlock()
+		}(user.Id)
+	}
+	wg.Wait()
+
+	return tokens
+}
+
+// updateTokenMetrics exports expiry and last use of each personal access token
+func (e *UsersExporter) updateTokenMetrics(users []api.User, tokens map[string][]api.PersonalAccessToken) {
+	totalTokens := 0
+	neverUsed := 0
+
+	for _, user := range users {
+		for _, token := range tokens[user.Id] {
+			totalTokens++
+			e.userTokenExpiration.WithLabelValues(user.Id, token.Id, token.Name).Set(float64(token.ExpirationDate.Unix()))
+			if token.LastUsed == nil || token.LastUsed.IsZero() {
+				neverUsed++
+				continue
+			}
+			e.userTokenLastUsed.WithLabelValues(user.Id, token.Id, token.Name).Set(float64(token.LastUsed.Unix()))
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"users_with_tokens": len(tokens),
+		"total_tokens":      totalTokens,
+		"never_used_tokens": neverUsed,
+	}).Debug("Updated user token metrics")
+}
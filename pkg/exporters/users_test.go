@@ -1,10 +1,12 @@
 package exporters
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -382,3 +384,320 @@ func TestUsersExporter_MetricsReset(t *testing.T) {
 		}
 	}
 }
+
+func TestUsersExporter_PeerOwnership(t *testing.T) {
+	client := nbclient.New("https://api.netbird.io", "test-token")
+	exporter := NewUsersExporter(client)
+
+	serviceUser := true
+	users := []api.User{
+		{Id: "user1", Email: "alice@example.com"},
+		{Id: "user2", Email: "bob@example.com"},
+		{Id: "user3", Email: "carol@example.com", IsBlocked: true},
+		{Id: "svc1", Email: "automation", IsServiceUser: &serviceUser},
+	}
+
+	peers := []api.Peer{
+		{Id: "peer1", UserId: "user1", Connected: true},
+		{Id: "peer2", UserId: "user1", Connected: false},
+		{Id: "peer3", UserId: "user3", Connected: false},
+		{Id: "peer4", UserId: "deleted-user", Connected: true},
+		{Id: "peer5", Connected: true},
+	}
+	for i := 0; i < 6; i++ {
+		peers = append(peers, api.Peer{Id: "bulk", UserId: "user1", Connected: true})
+	}
+
+	exporter.updatePeerOwnershipMetrics(users, peers)
+
+	connected, _ := gatherMetricValue(t, exporter.userPeers, "netbird_user_peers", map[string]string{"user_id": "user1", "connected": "true"})
+	if connected != 7 {
+		t.Errorf("Expected user1 to own 7 connected peers, got %f", connected)
+	}
+	disconnected, _ := gatherMetricValue(t, exporter.userPeers, "netbird_user_peers", map[string]string{"user_id": "user1", "connected": "false"})
+	if disconnected != 1 {
+		t.Errorf("Expected user1 to own 1 disconnected peer, got %f", disconnected)
+	}
+
+	tests := []struct {
+		collector prometheus.Collector
+		name      string
+		labels    map[string]string
+		expected  float64
+	}{
+		{exporter.usersWithoutPeers, "netbird_users_without_peers", map[string]string{}, 1},
+		{exporter.usersPeersAbove, "netbird_users_peers_above_threshold", map[string]string{"threshold": "5"}, 1},
+		{exporter.usersBlockedWithPeers, "netbird_users_blocked_with_peers", map[string]string{}, 1},
+		{exporter.peersUnknownUser, "netbird_peers_unknown_user", map[string]string{}, 1},
+	}
+	for _, tt := range tests {
+		value, found := gatherMetricValue(t, tt.collector, tt.name, tt.labels)
+		if !found || value != tt.expected {
+			t.Errorf("Expected %s to be %f, got %f (found=%v)", tt.name, tt.expected, value, found)
+		}
+	}
+}
+
+func TestUsersExporter_Tokens(t *testing.T) {
+	expires := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	lastUsed := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	var mu sync.Mutex
+	requests := make(map[string]int)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/users/current":
+			_, _ = w.Write([]byte(`{"id":"admin1"}`))
+		case "/api/users/svc1/tokens":
+			tokens := []api.PersonalAccessToken{
+				{Id: "token1", Name: "exporter", ExpirationDate: expires, LastUsed: &lastUsed},
+				{Id: "token2", Name: "unused", ExpirationDate: expires},
+			}
+			if err := json.NewEncoder(w).Encode(tokens); err != nil {
+				t.Errorf("Failed to encode tokens: %v", err)
+			}
+		case "/api/users/admin1/tokens":
+			tokens := []api.PersonalAccessToken{{Id: "token3", Name: "own", ExpirationDate: expires}}
+			if err := json.NewEncoder(w).Encode(tokens); err != nil {
+				t.Errorf("Failed to encode tokens: %v", err)
+			}
+		default:
+			// NetBird refuses to list the tokens of other regular users
+			http.Error(w, "Forbidden", http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	client := nbclient.New(server.URL, "test-token")
+	opts := DefaultOptions()
+	opts.UserTokens = true
+	exporter := NewUsersExporterWithOptions(client, opts)
+
+	serviceUser := true
+	users := []api.User{{Id: "svc1", IsServiceUser: &serviceUser}, {Id: "admin1"}, {Id: "user1"}}
+	for i := 0; i < 2; i++ {
+		exporter.updateTokenMetrics(users, exporter.fetchUserTokens(context.Background(), exporter.tokenUsers(context.Background(), users)))
+	}
+
+	expiration, found := gatherMetricValue(t, exporter.userTokenExpiration, "netbird_user_token_expiration_timestamp", map[string]string{"token_id": "token1"})
+	if !found || expiration != float64(expires.Unix()) {
+		t.Errorf("Expected token expiration %d, got %f (found=%v)", expires.Unix(), expiration, found)
+	}
+	if _, found := gatherMetricValue(t, exporter.userTokenExpiration, "netbird_user_token_expiration_timestamp", map[string]string{"token_id": "token3"}); !found {
+		t.Error("Expected the exporter's own tokens to be exported")
+	}
+	used, found := gatherMetricValue(t, exporter.userTokenLastUsed, "netbird_user_token_last_used_timestamp", map[string]string{"token_id": "token1"})
+	if !found || used != float64(lastUsed.Unix()) {
+		t.Errorf("Expected token last use %d, got %f (found=%v)", lastUsed.Unix(), used, found)
+	}
+	if _, found := gatherMetricValue(t, exporter.userTokenLastUsed, "netbird_user_token_last_used_timestamp", map[string]string{"token_id": "token2"}); found {
+		t.Error("Expected no last used series for a token that was never used")
+	}
+	if fetchErrors, found := gatherMetricValue(t, exporter.scrapeErrorsTotal, "netbird_users_scrape_errors_total", map[string]string{"error_type": "fetch_tokens"}); found {
+		t.Errorf("Expected no token fetch errors, got %f", fetchErrors)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if requests["/api/users/user1/tokens"] != 0 {
+		t.Error("Expected no token request for a regular user other than the current one")
+	}
+	if requests["/api/users/current"] != 1 {
+		t.Errorf("Expected the current user to be resolved once, got %d requests", requests["/api/users/current"])
+	}
+}
+
+func TestUsersExporter_Activity(t *testing.T) {
+	client := nbclient.New("https://api.netbird.io", "test-token")
+	exporter := NewUsersExporter(client)
+
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) *time.Time {
+		login := now.Add(-time.Duration(days) * 24 * time.Hour)
+		return &login
+	}
+	serviceUser := true
+	exporter.updateActivityMetrics([]api.User{
+		{Id: "user1", LastLogin: daysAgo(1)},
+		{Id: "user2", LastLogin: daysAgo(45)},
+		{Id: "user3", LastLogin: daysAgo(200)},
+		{Id: "user4"},
+		{Id: "svc1", IsServiceUser: &serviceUser},
+		{Id: "svc2", IsServiceUser: &serviceUser, LastLogin: daysAgo(365)},
+	}, now)
+
+	tests := []struct {
+		period   string
+		expected float64
+	}{
+		{"30d", 2},
+		{"90d", 1},
+		{"180d", 1},
+	}
+	for _, tt := range tests {
+		value, found := gatherMetricValue(t, exporter.usersInactive, "netbird_users_inactive", map[string]string{"period": tt.period})
+		if !found || value != tt.expected {
+			t.Errorf("Expected %f users inactive for %s, got %f (found=%v)", tt.expected, tt.period, value, found)
+		}
+	}
+
+	neverLoggedIn, _ := gatherMetricValue(t, exporter.usersNeverLoggedIn, "netbird_users_never_logged_in", nil)
+	if neverLoggedIn != 1 {
+		t.Errorf("Expected 1 user that never logged in, got %f", neverLoggedIn)
+	}
+}
+
+func TestUsersExporter_Roles(t *testing.T) {
+	client := nbclient.New("https://api.netbird.io", "test-token")
+	exporter := NewUsersExporter(client)
+
+	serviceUser := true
+	exporter.updateRoleMetrics([]api.User{
+		{Id: "owner1", Role: "owner"},
+		{Id: "admin1", Role: "admin"},
+		{Id: "admin2", Role: "admin", IsBlocked: true},
+		{Id: "svc1", Role: "admin", IsServiceUser: &serviceUser},
+		{Id: "user1", Role: "user"},
+	})
+
+	roleTests := []struct {
+		role     string
+		expected float64
+	}{
+		{"owner", 1},
+		{"admin", 1},
+		{"user", 1},
+	}
+	for _, tt := range roleTests {
+		value, found := gatherMetricValue(t, exporter.usersActiveByRole, "netbird_users_active_by_role", map[string]string{"role": tt.role})
+		if !found || value != tt.expected {
+			t.Errorf("Expected %f active %s users, got %f (found=%v)", tt.expected, tt.role, value, found)
+		}
+	}
+
+	// Privileged users on the first poll are the baseline
+	if _, found := gatherMetricValue(t, exporter.privilegedChanges, "netbird_users_privileged_changes_total", nil); found {
+		t.Error("Expected no privileged changes on the first poll")
+	}
+
+	exporter.usersActiveByRole.Reset()
+	exporter.privilegedChangeInfo.Reset()
+	exporter.updateRoleMetrics([]api.User{
+		{Id: "owner1", Role: "owner"},
+		{Id: "admin1", Role: "admin"},
+		{Id: "user1", Role: "admin"},
+		{Id: "user2", Role: "owner"},
+	})
+
+	promoted, _ := gatherMetricValue(t, exporter.privilegedChanges, "netbird_users_privileged_changes_total", map[string]string{"role": "admin"})
+	if promoted != 1 {
+		t.Errorf("Expected 1 admin grant, got %f", promoted)
+	}
+	added, _ := gatherMetricValue(t, exporter.privilegedChanges, "netbird_users_privileged_changes_total", map[string]string{"role": "owner"})
+	if added != 1 {
+		t.Errorf("Expected 1 owner grant, got %f", added)
+	}
+	if _, found := gatherMetricValue(t, exporter.privilegedChangeInfo, "netbird_user_privileged_change_info", map[string]string{"user_id": "user1", "previous_role": "user", "role": "admin"}); !found {
+		t.Error("Expected info series for the promoted user")
+	}
+	if _, found := gatherMetricValue(t, exporter.privilegedChangeInfo, "netbird_user_privileged_change_info", map[string]string{"user_id": "user2", "previous_role": "none"}); !found {
+		t.Error("Expected info series for the new owner")
+	}
+
+	// Demoted users no longer have an info series
+	exporter.privilegedChangeInfo.Reset()
+	exporter.updateRoleMetrics([]api.User{{Id: "user1", Role: "user"}, {Id: "user2", Role: "owner"}})
+	if _, found := gatherMetricValue(t, exporter.privilegedChangeInfo, "netbird_user_privileged_change_info", map[string]string{"user_id": "user1"}); found {
+		t.Error("Expected info series to be removed after demotion")
+	}
+}
+
+func TestUsersExporter_Pending(t *testing.T) {
+	client := nbclient.New("https://api.netbird.io", "test-token")
+	exporter := NewUsersExporter(client)
+
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	exporter.updatePendingMetrics([]api.User{
+		{Id: "user1", Status: api.UserStatusInvited},
+		{Id: "user2", Status: api.UserStatusActive, PendingApproval: true},
+		{Id: "user3", Status: api.UserStatusActive},
+	}, start)
+
+	exporter.updatePendingMetrics([]api.User{
+		{Id: "user1", Status: api.UserStatusInvited},
+		{Id: "user2", Status: api.UserStatusActive},
+		{Id: "user3", Status: api.UserStatusActive},
+		{Id: "user4", Status: api.UserStatusInvited},
+	}, start.Add(2*time.Hour))
+
+	tests := []struct {
+		collector prometheus.Collector
+		name      string
+		kind      string
+		expected  float64
+	}{
+		{exporter.usersPending, "netbird_users_pending", "invited", 2},
+		{exporter.usersPending, "netbird_users_pending", "approval", 0},
+		{exporter.usersPendingOldestAge, "netbird_users_pending_oldest_age_seconds", "invited", 7200},
+		{exporter.usersPendingOldestAge, "netbird_users_pending_oldest_age_seconds", "approval", 0},
+	}
+	for _, tt := range tests {
+		value, found := gatherMetricValue(t, tt.collector, tt.name, map[string]string{"kind": tt.kind})
+		if !found || value != tt.expected {
+			t.Errorf("Expected %s{kind=%q} to be %f, got %f (found=%v)", tt.name, tt.kind, tt.expected, value, found)
+		}
+	}
+
+	if len(exporter.pendingSince) != 2 {
+		t.Errorf("Expected approved users to be forgotten, tracking %d users", len(exporter.pendingSince))
+	}
+}
+
+func TestUsersExporter_PermissionAggregation(t *testing.T) {
+	client := nbclient.New("https://api.netbird.io", "test-token")
+	opts := DefaultOptions()
+	opts.UserPermissionsPerUser = false
+	opts.UserPermissionsModules = []string{"users"}
+	exporter := NewUsersExporterWithOptions(client, opts)
+
+	permissions := func(peersRead, usersCreate bool) *api.UserPermissions {
+		return &api.UserPermissions{Modules: map[string]map[string]bool{
+			"peers": {"read": peersRead},
+			"users": {"create": usersCreate},
+		}}
+	}
+	exporter.updateMetrics([]api.User{
+		{Id: "user1", Email: "alice@example.com", Permissions: permissions(true, true)},
+		{Id: "user2", Email: "bob@example.com", Permissions: permissions(true, false)},
+		{Id: "user3", Email: "carol@example.com", Permissions: permissions(false, false)},
+	})
+
+	tests := []struct {
+		module     string
+		permission string
+		expected   float64
+	}{
+		{"peers", "read", 2},
+		{"users", "create", 1},
+	}
+	for _, tt := range tests {
+		value, found := gatherMetricValue(t, exporter.usersWithPermission, "netbird_users_with_permission", map[string]string{"module": tt.module, "permission": tt.permission})
+		if !found || value != tt.expected {
+			t.Errorf("Expected %f users with %s/%s, got %f (found=%v)", tt.expected, tt.module, tt.permission, value, found)
+		}
+	}
+
+	// Only the allowlisted module is exported per user
+	if _, found := gatherMetricValue(t, exporter.usersPermissions, "netbird_user_permissions", map[string]string{"module": "peers"}); found {
+		t.Error("Expected per-user peers permissions to be disabled")
+	}
+	if _, found := gatherMetricValue(t, exporter.usersPermissions, "netbird_user_permissions", map[string]string{"module": "users", "user_id": "user1"}); !found {
+		t.Error("Expected per-user permissions for the allowlisted users module")
+	}
+}
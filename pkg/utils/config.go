@@ -1,6 +1,10 @@
 package utils
 
-import "os"
+import (
+	"os"
+	"strconv"
+	"strings"
+)
 
 // GetEnvWithDefault returns environment variable value or default
 func GetEnvWithDefault(key, defaultValue string) string {
@@ -9,3 +13,56 @@ func GetEnvWithDefault(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// GetEnvBool returns environment variable parsed as a boolean or default if unset or invalid
+func GetEnvBool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// GetEnvInt returns environment variable parsed as an integer or default if unset or invalid
+func GetEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// GetEnvList returns environment variable split on commas with blank entries removed, or default if unset
+func GetEnvList(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// GetEnvMap returns environment variable parsed as comma-separated key=value pairs, or default if unset.
+// Entries without a key or an equals sign are ignored.
+func GetEnvMap(key string, defaultValue map[string]string) map[string]string {
+	items := GetEnvList(key, nil)
+	if items == nil {
+		return defaultValue
+	}
+
+	values := make(map[string]string, len(items))
+	for _, item := range items {
+		k, v, ok := strings.Cut(item, "=")
+		if k = strings.TrimSpace(k); !ok || k == "" {
+			continue
+		}
+		values[k] = strings.TrimSpace(v)
+	}
+	return values
+}
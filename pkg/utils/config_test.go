@@ -2,6 +2,7 @@ package utils
 
 import (
 	"os"
+	"reflect"
 	"testing"
 )
 
@@ -208,3 +209,101 @@ func TestGetEnvWithDefault_EdgeCases(t *testing.T) {
 		})
 	}
 }
+
+func TestGetEnvBool(t *testing.T) {
+	tests := []struct {
+		name         string
+		envValue     string
+		defaultValue bool
+		expected     bool
+	}{
+		{name: "true value", envValue: "true", defaultValue: false, expected: true},
+		{name: "numeric true value", envValue: "1", defaultValue: false, expected: true},
+		{name: "false value", envValue: "false", defaultValue: true, expected: false},
+		{name: "unset uses default", envValue: "", defaultValue: true, expected: true},
+		{name: "invalid uses default", envValue: "maybe", defaultValue: true, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TEST_BOOL_VAR", tt.envValue)
+
+			result := GetEnvBool("TEST_BOOL_VAR", tt.defaultValue)
+			if result != tt.expected {
+				t.Errorf("GetEnvBool(%q) = %v, want %v", tt.envValue, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetEnvInt(t *testing.T) {
+	tests := []struct {
+		name         string
+		envValue     string
+		defaultValue int
+		expected     int
+	}{
+		{name: "valid value", envValue: "42", defaultValue: 5, expected: 42},
+		{name: "negative value", envValue: "-1", defaultValue: 5, expected: -1},
+		{name: "unset uses default", envValue: "", defaultValue: 5, expected: 5},
+		{name: "invalid uses default", envValue: "ten", defaultValue: 5, expected: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TEST_INT_VAR", tt.envValue)
+
+			result := GetEnvInt("TEST_INT_VAR", tt.defaultValue)
+			if result != tt.expected {
+				t.Errorf("GetEnvInt(%q) = %d, want %d", tt.envValue, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetEnvList(t *testing.T) {
+	tests := []struct {
+		name     string
+		envValue string
+		expected []string
+	}{
+		{name: "single value", envValue: "22", expected: []string{"22"}},
+		{name: "multiple values with spaces", envValue: "22, 3389 ,5432", expected: []string{"22", "3389", "5432"}},
+		{name: "blank entries removed", envValue: "a,,b,", expected: []string{"a", "b"}},
+		{name: "unset uses default", envValue: "", expected: []string{"default"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TEST_LIST_VAR", tt.envValue)
+
+			result := GetEnvList("TEST_LIST_VAR", []string{"default"})
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("GetEnvList(%q) = %v, want %v", tt.envValue, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetEnvMap(t *testing.T) {
+	tests := []struct {
+		name     string
+		envValue string
+		expected map[string]string
+	}{
+		{name: "pairs", envValue: "raspbian=linux, haiku = other", expected: map[string]string{"raspbian": "linux", "haiku": "other"}},
+		{name: "invalid entries ignored", envValue: "novalue,=x,a=b", expected: map[string]string{"a": "b"}},
+		{name: "unset uses default", envValue: "", expected: map[string]string{"k": "v"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TEST_MAP_VAR", tt.envValue)
+
+			result := GetEnvMap("TEST_MAP_VAR", map[string]string{"k": "v"})
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("GetEnvMap(%q) = %v, want %v", tt.envValue, result, tt.expected)
+			}
+		})
+	}
+}